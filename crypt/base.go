@@ -2,6 +2,7 @@ package crypt
 
 import (
 	"bytes"
+	"crypto/subtle"
 )
 
 // encrypt mode
@@ -14,18 +15,55 @@ const (
 	//cbc
 	CBC Mode = "CBC"
 	//ecb
-	ECB = "ECB"
+	ECB Mode = "ECB"
+	//gcm, authenticated encryption without padding
+	GCM Mode = "GCM"
+	//ctr, stream mode without padding
+	CTR Mode = "CTR"
+	//cfb, stream mode without padding
+	CFB Mode = "CFB"
+	//ofb, stream mode without padding
+	OFB Mode = "OFB"
 
 	//PKCS5 padding
 	PKCS5 Padding = "PKCS5"
 	//PKCS7 padding
-	PKCS7 = "PKCS7"
+	PKCS7 Padding = "PKCS7"
 	//ZERO 0 padding
-	ZERO = "ZERO"
+	ZERO Padding = "ZERO"
 	//NONE padding
-	NONE = "NONE"
+	NONE Padding = "NONE"
 )
 
+// pad 根据填充方式填充数据, 不修改原数据
+func pad(data []byte, blockSize int, padding Padding) []byte {
+	data = data[:len(data):len(data)]
+	switch padding {
+	case PKCS5:
+		return PKCS5Padding(data, blockSize)
+	case PKCS7:
+		return PKCS7Padding(data, blockSize)
+	case ZERO:
+		return ZeroPadding(data, blockSize)
+	default:
+		return data
+	}
+}
+
+// unpad 根据填充方式严格校验并去除填充
+func unpad(data []byte, blockSize int, padding Padding) ([]byte, error) {
+	switch padding {
+	case PKCS5:
+		return PKCS5UnPaddingStrict(data, blockSize)
+	case PKCS7:
+		return PKCS7UnPaddingStrict(data, blockSize)
+	case ZERO:
+		return ZeroUnPadding(data), nil
+	default:
+		return data, nil
+	}
+}
+
 // PKCS5Padding
 func PKCS5Padding(cipherText []byte, blockSize int) []byte {
 	padding := blockSize - len(cipherText)%blockSize
@@ -34,9 +72,14 @@ func PKCS5Padding(cipherText []byte, blockSize int) []byte {
 }
 
 // PKCS5UnPadding
+// 不校验填充内容, 填充长度非法时原样返回, 需要校验时使用PKCS5UnPaddingStrict
 func PKCS5UnPadding(encrypt []byte) []byte {
-	padding := encrypt[len(encrypt)-1]
-	return encrypt[:len(encrypt)-int(padding)]
+	return PKCS7UnPadding(encrypt)
+}
+
+// PKCS5UnPaddingStrict 严格校验并去除PKCS5填充, 同PKCS7UnPaddingStrict
+func PKCS5UnPaddingStrict(encrypt []byte, blockSize int) ([]byte, error) {
+	return PKCS7UnPaddingStrict(encrypt, blockSize)
 }
 
 // PKCS7Padding
@@ -47,12 +90,41 @@ func PKCS7Padding(ciphertext []byte, blockSize int) []byte {
 }
 
 // PKCS7UnPadding
+// 不校验填充内容, 填充长度非法时原样返回, 需要校验时使用PKCS7UnPaddingStrict
 func PKCS7UnPadding(origData []byte) []byte {
 	length := len(origData)
+	if length == 0 {
+		return origData
+	}
 	unpadding := int(origData[length-1])
+	if unpadding == 0 || unpadding > length {
+		return origData
+	}
 	return origData[:(length - unpadding)]
 }
 
+// PKCS7UnPaddingStrict 严格校验并去除PKCS7填充
+// 数据长度必须是blockSize的整数倍, 且每个填充字节都必须等于填充长度, 否则返回ErrInvalidPadding
+// 校验过程为常量时间, 不会因为填充内容不同而泄露时间信息
+func PKCS7UnPaddingStrict(origData []byte, blockSize int) ([]byte, error) {
+	length := len(origData)
+	if blockSize <= 0 || blockSize > 255 || length == 0 || length%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	unpadding := int(origData[length-1])
+	good := subtle.ConstantTimeLessOrEq(1, unpadding) & subtle.ConstantTimeLessOrEq(unpadding, blockSize)
+	for i := 1; i <= blockSize; i++ {
+		// 填充范围内的字节必须等于填充长度
+		inPadding := subtle.ConstantTimeLessOrEq(i, unpadding)
+		equal := subtle.ConstantTimeByteEq(origData[length-i], byte(unpadding))
+		good &= subtle.ConstantTimeSelect(inPadding, equal, 1)
+	}
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return origData[:(length - unpadding)], nil
+}
+
 // ZeroPadding 补齐0
 func ZeroPadding(ciphertext []byte, blockSize int) []byte {
 	padding := blockSize - len(ciphertext)%blockSize
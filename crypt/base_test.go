@@ -0,0 +1,40 @@
+package crypt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnPadding(t *testing.T) {
+	// lenient unpadding never panics
+	assert.Equal(t, []byte{}, PKCS5UnPadding([]byte{}))
+	assert.Equal(t, []byte{1, 2, 9}, PKCS7UnPadding([]byte{1, 2, 9}))
+	assert.Equal(t, []byte{1, 2, 0}, PKCS7UnPadding([]byte{1, 2, 0}))
+	assert.Equal(t, []byte{1}, PKCS7UnPadding([]byte{1, 2, 2}))
+}
+
+func TestUnPaddingStrict(t *testing.T) {
+	for i := 0; i <= 16; i++ {
+		data := make([]byte, i)
+		padded := PKCS7Padding(data, 16)
+		unpadded, err := PKCS7UnPaddingStrict(padded, 16)
+		assert.Nil(t, err)
+		assert.Equal(t, data, unpadded)
+	}
+
+	cases := [][]byte{
+		nil,
+		{},
+		{1, 2, 3, 1},
+		append(make([]byte, 15), 0),
+		append(make([]byte, 15), 17),
+		append(make([]byte, 14), 3, 2),
+		append(make([]byte, 13), 2, 3, 3),
+	}
+	for _, c := range cases {
+		_, err := PKCS5UnPaddingStrict(c, 16)
+		assert.True(t, errors.Is(err, ErrInvalidPadding), "%x", c)
+	}
+}
@@ -0,0 +1,271 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+)
+
+// CertOptions 证书及证书请求的参数
+type CertOptions struct {
+	// 证书主体, CommonName为空时使用第一个DNSNames
+	Subject pkix.Name
+	// 主体备用名称(SAN)
+	DNSNames    []string
+	IPAddresses []net.IP
+	// 生效时间, 默认为当前时间
+	NotBefore time.Time
+	// 有效期, 默认365天
+	ValidFor time.Duration
+	// 是否为CA证书
+	IsCA bool
+	// 证书序列号, 默认随机生成
+	SerialNumber *big.Int
+}
+
+// template 生成证书模板, pub为证书的公钥, 只有rsa公钥可以用于密钥加密
+func (o *CertOptions) template(pub crypto.PublicKey) (*x509.Certificate, error) {
+	serial := o.SerialNumber
+	if serial == nil {
+		var err error
+		if serial, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128)); err != nil {
+			return nil, err
+		}
+	}
+	notBefore := o.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now()
+	}
+	validFor := o.ValidFor
+	if validFor <= 0 {
+		validFor = 365 * 24 * time.Hour
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               o.subject(),
+		DNSNames:              o.DNSNames,
+		IPAddresses:           o.IPAddresses,
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(validFor),
+		BasicConstraintsValid: true,
+		IsCA:                  o.IsCA,
+	}
+	if o.IsCA {
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	} else {
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		if _, ok := pub.(*rsa.PublicKey); ok {
+			tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+	return tmpl, nil
+}
+
+// subject 返回证书主体, CommonName为空时使用第一个DNSNames
+func (o *CertOptions) subject() pkix.Name {
+	subject := o.Subject
+	if subject.CommonName == "" && len(o.DNSNames) > 0 {
+		subject.CommonName = o.DNSNames[0]
+	}
+	return subject
+}
+
+// CreateSelfSignedCert 使用私钥生成自签名证书, key可以是rsa, ecdsa或ed25519私钥
+//
+//	key, _ := GenerateRSAKey(2048)
+//	cert, err := CreateSelfSignedCert(key, CertOptions{DNSNames: []string{"localhost"}})
+func CreateSelfSignedCert(key crypto.Signer, opts CertOptions) (*x509.Certificate, error) {
+	tmpl, err := opts.template(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return createCert(tmpl, tmpl, key.Public(), key)
+}
+
+// CreateCert 使用CA证书和CA私钥为公钥签发证书
+func CreateCert(pub crypto.PublicKey, opts CertOptions, ca *x509.Certificate, caKey crypto.Signer) (*x509.Certificate, error) {
+	tmpl, err := opts.template(pub)
+	if err != nil {
+		return nil, err
+	}
+	return createCert(tmpl, ca, pub, caKey)
+}
+
+// createCert 签发证书并解析结果
+func createCert(tmpl, parent *x509.Certificate, pub crypto.PublicKey, key crypto.Signer) (*x509.Certificate, error) {
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// CreateCSR 生成证书请求, 使用opts中的Subject, DNSNames和IPAddresses
+func CreateCSR(key crypto.Signer, opts CertOptions) (*x509.CertificateRequest, error) {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:     opts.subject(),
+		DNSNames:    opts.DNSNames,
+		IPAddresses: opts.IPAddresses,
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificateRequest(der)
+}
+
+// SignCSR 校验证书请求的签名, 并使用CA签发证书
+// 证书的主体和备用名称来自证书请求, opts中只使用有效期, 序列号和IsCA
+func SignCSR(csr *x509.CertificateRequest, opts CertOptions, ca *x509.Certificate, caKey crypto.Signer) (*x509.Certificate, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrVerification, err.Error())
+	}
+	opts.Subject = csr.Subject
+	opts.DNSNames = csr.DNSNames
+	opts.IPAddresses = csr.IPAddresses
+	return CreateCert(csr.PublicKey, opts, ca, caKey)
+}
+
+// ParseCSR 读取PEM或DER格式的证书请求
+func ParseCSR(in []byte) (*x509.CertificateRequest, error) {
+	if block, _ := pem.Decode(in); block != nil {
+		in = block.Bytes
+	}
+	return x509.ParseCertificateRequest(in)
+}
+
+// marshal certificate, type "CERTIFICATE" in pem format
+func MarshalCert(cert *x509.Certificate, format KeyFormat) ([]byte, error) {
+	return encodeKey(cert.Raw, "CERTIFICATE", format)
+}
+
+// marshal certificate request, type "CERTIFICATE REQUEST" in pem format
+func MarshalCSR(csr *x509.CertificateRequest, format KeyFormat) ([]byte, error) {
+	return encodeKey(csr.Raw, "CERTIFICATE REQUEST", format)
+}
+
+// ParseCertificates 读取证书, 支持包含多个证书的PEM文件和DER格式
+// PEM中非CERTIFICATE类型的块(如私钥)会被忽略
+func ParseCertificates(in []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := in
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 && len(rest) == len(in) {
+		// 不是PEM格式, 按DER读取
+		var err error
+		if certs, err = x509.ParseCertificates(in); err != nil {
+			return nil, err
+		}
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs, nil
+}
+
+// NewCertPool 使用PEM或DER格式的证书创建证书池, 用作VerifyChain的根证书
+func NewCertPool(in []byte) (*x509.CertPool, error) {
+	certs, err := ParseCertificates(in)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// VerifyChain 使用根证书池校验证书链, chain[0]为待校验的证书, 其余为中间证书
+// dnsName不为空时同时校验证书的主机名, 不校验证书的用途
+func VerifyChain(chain []*x509.Certificate, roots *x509.CertPool, dnsName string) error {
+	if len(chain) == 0 {
+		return errors.New("empty certificate chain")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		DNSName:       dnsName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// CertSummary 证书摘要信息
+type CertSummary struct {
+	Subject     string
+	Issuer      string
+	Serial      string
+	DNSNames    []string
+	IPAddresses []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	IsCA        bool
+}
+
+// SummarizeCert 获取证书的摘要信息
+func SummarizeCert(cert *x509.Certificate) *CertSummary {
+	s := &CertSummary{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		Serial:    hex.EncodeToString(cert.SerialNumber.Bytes()),
+		DNSNames:  cert.DNSNames,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		IsCA:      cert.IsCA,
+	}
+	for _, ip := range cert.IPAddresses {
+		s.IPAddresses = append(s.IPAddresses, ip.String())
+	}
+	return s
+}
+
+// Expired 证书在指定时间是否已过期
+func (s *CertSummary) Expired(now time.Time) bool {
+	return now.After(s.NotAfter)
+}
+
+// String 返回多行的可读信息
+func (s *CertSummary) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Subject:    %s\n", s.Subject)
+	fmt.Fprintf(&sb, "Issuer:     %s\n", s.Issuer)
+	fmt.Fprintf(&sb, "Serial:     %s\n", s.Serial)
+	fmt.Fprintf(&sb, "SANs:       %s\n", strings.Join(append(append([]string(nil), s.DNSNames...), s.IPAddresses...), ", "))
+	fmt.Fprintf(&sb, "Not Before: %s\n", s.NotBefore.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Not After:  %s", s.NotAfter.UTC().Format(time.RFC3339))
+	if s.Expired(time.Now()) {
+		sb.WriteString(" (expired)")
+	}
+	fmt.Fprintf(&sb, "\nCA:         %t", s.IsCA)
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+package crypt
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfSignedCert(t *testing.T) {
+	cert, err := CreateSelfSignedCert(testRSAKey, CertOptions{
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ValidFor:    24 * time.Hour,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", cert.Subject.CommonName)
+	assert.Equal(t, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment, cert.KeyUsage)
+
+	// key encipherment is only for rsa keys
+	ecKey, _ := ParseECPrivateKey([]byte(testECKey))
+	ecCert, err := CreateSelfSignedCert(ecKey, CertOptions{DNSNames: []string{"localhost"}})
+	assert.Nil(t, err)
+	assert.Equal(t, x509.KeyUsageDigitalSignature, ecCert.KeyUsage)
+
+	out, err := MarshalCert(cert, PEM)
+	assert.Nil(t, err)
+	roots, err := NewCertPool(out)
+	assert.Nil(t, err)
+	assert.Nil(t, VerifyChain([]*x509.Certificate{cert}, roots, "localhost"))
+	assert.Nil(t, VerifyChain([]*x509.Certificate{cert}, roots, "127.0.0.1"))
+	assert.NotNil(t, VerifyChain([]*x509.Certificate{cert}, roots, "example.com"))
+
+	summary := SummarizeCert(cert)
+	assert.Equal(t, "CN=localhost", summary.Subject)
+	assert.Equal(t, []string{"127.0.0.1"}, summary.IPAddresses)
+	assert.False(t, summary.Expired(time.Now()))
+	assert.True(t, summary.Expired(time.Now().Add(25*time.Hour)))
+	assert.True(t, strings.Contains(summary.String(), "SANs:       localhost, 127.0.0.1"))
+}
+
+func TestCertChain(t *testing.T) {
+	caKey, _ := ParseECPrivateKey([]byte(testECKey))
+	ca, err := CreateSelfSignedCert(caKey, CertOptions{
+		Subject: pkix.Name{CommonName: "zk root ca", Organization: []string{"zk"}},
+		IsCA:    true,
+	})
+	assert.Nil(t, err)
+	assert.True(t, ca.IsCA)
+
+	csr, err := CreateCSR(testRSAKey, CertOptions{DNSNames: []string{"api.internal", "*.api.internal"}})
+	assert.Nil(t, err)
+	csrPEM, err := MarshalCSR(csr, PEM)
+	assert.Nil(t, err)
+	csr, err = ParseCSR(csrPEM)
+	assert.Nil(t, err)
+
+	leaf, err := SignCSR(csr, CertOptions{}, ca, caKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "api.internal", leaf.Subject.CommonName)
+	assert.Equal(t, "CN=zk root ca,O=zk", leaf.Issuer.String())
+
+	// bundle of leaf and ca with a private key in between
+	leafPEM, _ := MarshalCert(leaf, PEM)
+	caPEM, _ := MarshalCert(ca, PEM)
+	keyPEM, _ := MarshalPKCS8PrivateKey(testRSAKey, PEM)
+	bundle := append(append(leafPEM, keyPEM...), caPEM...)
+	certs, err := ParseCertificates(bundle)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(certs))
+
+	roots, _ := NewCertPool(caPEM)
+	assert.Nil(t, VerifyChain(certs[:1], roots, "x.api.internal"))
+	assert.NotNil(t, VerifyChain(certs[:1], x509.NewCertPool(), "api.internal"))
+
+	// der
+	certs, err = ParseCertificates(leaf.Raw)
+	assert.Nil(t, err)
+	assert.Equal(t, leaf.Raw, certs[0].Raw)
+	_, err = ParseCertificates(keyPEM)
+	assert.NotNil(t, err)
+	_, err = ParseCertificates(nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,282 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"fmt"
+	"sync"
+
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// symmetric algorithm
+type Algorithm string
+
+const (
+	//aes, key length 16/24/32
+	AES Algorithm = "AES"
+	//des, key length 8
+	DES Algorithm = "DES"
+	//3des, key length 24
+	DES3 Algorithm = "3DES"
+	//sm4, key length 16
+	SM4 Algorithm = "SM4"
+)
+
+// newBlock 根据算法创建密码块
+func newBlock(alg Algorithm, key []byte) (cipher.Block, error) {
+	var block cipher.Block
+	var err error
+	switch alg {
+	case AES:
+		block, err = aes.NewCipher(key)
+	case DES:
+		block, err = des.NewCipher(key)
+	case DES3:
+		block, err = des.NewTripleDESCipher(key)
+	case SM4:
+		block, err = newSM4Block(key)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAlgorithm, alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err.Error())
+	}
+	return block, nil
+}
+
+// sm4Block gmsm的SM4实现使用内部缓冲区, 不能并发使用
+// 使用sync.Pool为并发的调用方提供独立的实例
+type sm4Block struct {
+	pool sync.Pool
+}
+
+func newSM4Block(key []byte) (cipher.Block, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	key = append([]byte(nil), key...)
+	b := &sm4Block{}
+	b.pool.New = func() any {
+		block, _ := sm4.NewCipher(key)
+		return block
+	}
+	b.pool.Put(block)
+	return b, nil
+}
+
+func (b *sm4Block) BlockSize() int { return sm4.BlockSize }
+
+func (b *sm4Block) Encrypt(dst, src []byte) {
+	block := b.pool.Get().(cipher.Block)
+	block.Encrypt(dst, src)
+	b.pool.Put(block)
+}
+
+func (b *sm4Block) Decrypt(dst, src []byte) {
+	block := b.pool.Get().(cipher.Block)
+	block.Decrypt(dst, src)
+	b.pool.Put(block)
+}
+
+// CipherOption 创建Cipher的可选参数
+type CipherOption func(c *Cipher)
+
+// WithMode 设置加密模式, 默认为CBC
+func WithMode(mode Mode) CipherOption {
+	return func(c *Cipher) {
+		c.mode = mode
+	}
+}
+
+// WithPadding 设置填充方式, 默认CBC/ECB为PKCS7, GCM及流模式为NONE
+func WithPadding(padding Padding) CipherOption {
+	return func(c *Cipher) {
+		c.padding = padding
+	}
+}
+
+// WithIV 设置初始化向量, GCM和CTR/CFB/OFB模式不能设置, 每次加密随机生成nonce或IV
+func WithIV(iv []byte) CipherOption {
+	return func(c *Cipher) {
+		c.iv = append([]byte(nil), iv...)
+	}
+}
+
+// WithAAD 设置GCM模式的附加认证数据
+func WithAAD(aad []byte) CipherOption {
+	return func(c *Cipher) {
+		c.aad = append([]byte(nil), aad...)
+	}
+}
+
+// Cipher 可复用的对称加密器
+// 创建时完成密钥扩展和参数校验, 创建后不可修改, 可并发使用
+type Cipher struct {
+	alg     Algorithm
+	mode    Mode
+	padding Padding
+	iv      []byte
+	aad     []byte
+
+	block cipher.Block
+	// GCM模式使用
+	aead cipher.AEAD
+}
+
+// NewCipher 创建对称加密器
+//
+//	c, err := NewCipher(AES, key, WithMode(CBC), WithPadding(PKCS7), WithIV(iv))
+//	encrypted, err := c.Encrypt(data)
+//
+// GCM模式的Cipher会重复使用, 固定的nonce会导致nonce重用, 因此不能设置IV;
+// 需要指定nonce时使用AESGCMEncrypt或SM4GCMEncrypt
+// CTR/CFB/OFB模式同理, 固定的IV会导致密钥流重用, 每次加密随机生成IV并作为密文前缀;
+// 需要指定IV时使用AESEncrypt或SM4Encrypt
+func NewCipher(alg Algorithm, key []byte, opts ...CipherOption) (*Cipher, error) {
+	c := applyCipherOptions(alg, opts)
+	if c.padding == "" {
+		c.padding = defaultPadding(c.mode)
+	}
+	if err := c.init(key); err != nil {
+		return nil, err
+	}
+	if c.mode == GCM && len(c.iv) > 0 {
+		return nil, fmt.Errorf("%w: fixed nonce is not allowed for reusable GCM cipher, use AESGCMEncrypt or SM4GCMEncrypt", ErrInvalidIV)
+	}
+	if isStreamMode(c.mode) && len(c.iv) > 0 {
+		return nil, fmt.Errorf("%w: fixed iv is not allowed for reusable %s cipher, use AESEncrypt or SM4Encrypt", ErrInvalidIV, c.mode)
+	}
+	return c, nil
+}
+
+// newCipher 创建对称加密器, GCM和CTR/CFB/OFB模式允许指定nonce或IV, 只用于一次性加密或解密
+// 填充方式不使用默认值, 必须明确指定
+func newCipher(alg Algorithm, key []byte, opts ...CipherOption) (*Cipher, error) {
+	c := applyCipherOptions(alg, opts)
+	if err := c.init(key); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// applyCipherOptions 创建Cipher并应用可选参数
+func applyCipherOptions(alg Algorithm, opts []CipherOption) *Cipher {
+	c := &Cipher{alg: alg, mode: CBC}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// defaultPadding 返回模式的默认填充方式
+func defaultPadding(mode Mode) Padding {
+	if mode == CBC || mode == ECB {
+		return PKCS7
+	}
+	return NONE
+}
+
+// init 创建分组加密器并校验参数
+func (c *Cipher) init(key []byte) error {
+	var err error
+	if c.block, err = newBlock(c.alg, key); err != nil {
+		return err
+	}
+	blockSize := c.block.BlockSize()
+
+	switch {
+	case c.mode == CBC || c.mode == ECB:
+		switch c.padding {
+		case PKCS5, PKCS7, ZERO, NONE:
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidPadding, c.padding)
+		}
+		if c.mode == CBC && len(c.iv) != blockSize {
+			return fmt.Errorf("%w: length must be %d in mode %s", ErrInvalidIV, blockSize, c.mode)
+		}
+	case c.mode == GCM || isStreamMode(c.mode):
+		if c.padding != NONE {
+			return fmt.Errorf("%w: %s in mode %s", ErrInvalidPadding, c.padding, c.mode)
+		}
+		if c.mode == GCM {
+			if c.aead, err = newGCM(c.block, len(c.iv)); err != nil {
+				return err
+			}
+		} else if len(c.iv) != 0 && len(c.iv) != blockSize {
+			return fmt.Errorf("%w: length must be %d in mode %s", ErrInvalidIV, blockSize, c.mode)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidMode, c.mode)
+	}
+
+	return nil
+}
+
+// Algorithm 返回加密算法
+func (c *Cipher) Algorithm() Algorithm {
+	return c.alg
+}
+
+// Mode 返回加密模式
+func (c *Cipher) Mode() Mode {
+	return c.mode
+}
+
+// BlockSize 返回分组长度
+func (c *Cipher) BlockSize() int {
+	return c.block.BlockSize()
+}
+
+// Encrypt 加密数据
+// GCM模式随机生成nonce并作为密文前缀, CTR/CFB/OFB模式随机生成iv并作为密文前缀
+func (c *Cipher) Encrypt(src []byte) ([]byte, error) {
+	switch c.mode {
+	case GCM:
+		return gcmSeal(c.aead, c.iv, src, c.aad)
+	case CTR, CFB, OFB:
+		return streamCrypt(c.block, c.iv, c.mode, src, true)
+	}
+
+	data := pad(src, c.block.BlockSize(), c.padding)
+	if len(data)%c.block.BlockSize() != 0 {
+		return nil, fmt.Errorf("%w: padding %s", ErrNotFullBlocks, c.padding)
+	}
+	dst := make([]byte, len(data))
+	c.blockMode(true).CryptBlocks(dst, data)
+	return dst, nil
+}
+
+// Decrypt 解密数据
+// GCM模式认证失败时返回ErrAuthentication, PKCS5/PKCS7填充校验失败时返回ErrInvalidPadding
+func (c *Cipher) Decrypt(src []byte) ([]byte, error) {
+	switch c.mode {
+	case GCM:
+		return gcmOpen(c.aead, c.iv, src, c.aad)
+	case CTR, CFB, OFB:
+		return streamCrypt(c.block, c.iv, c.mode, src, false)
+	}
+
+	if len(src)%c.block.BlockSize() != 0 {
+		return nil, ErrNotFullBlocks
+	}
+	dst := make([]byte, len(src))
+	c.blockMode(false).CryptBlocks(dst, src)
+	return unpad(dst, c.block.BlockSize(), c.padding)
+}
+
+// blockMode 创建CBC/ECB分组模式, BlockMode有状态, 每次加解密都需要重新创建
+func (c *Cipher) blockMode(encrypt bool) cipher.BlockMode {
+	switch {
+	case c.mode == ECB && encrypt:
+		return NewECBEncrypter(c.block)
+	case c.mode == ECB:
+		return NewECBDecrypter(c.block)
+	case encrypt:
+		return cipher.NewCBCEncrypter(c.block, c.iv)
+	default:
+		return cipher.NewCBCDecrypter(c.block, c.iv)
+	}
+}
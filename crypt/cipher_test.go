@@ -0,0 +1,149 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var cipherKeys = map[Algorithm][]byte{
+	AES:  []byte("1234567890abcdef"),
+	DES:  []byte("12345678"),
+	DES3: []byte("1234567890abcdef12345678"),
+	SM4:  []byte("1234567890abcdef"),
+}
+
+func TestCipher(t *testing.T) {
+	plain := []byte("reusable cipher for millions of small fields")
+	for alg, key := range cipherKeys {
+		iv := key[:len(cipherKeys[DES])]
+		if alg == AES || alg == SM4 {
+			iv = key
+		}
+		for _, mode := range []Mode{CBC, ECB, CTR, CFB, OFB, GCM} {
+			if mode == GCM && (alg == DES || alg == DES3) {
+				continue
+			}
+			nonce := iv
+			if mode == GCM || isStreamMode(mode) {
+				nonce = nil
+			}
+			c, err := NewCipher(alg, key, WithMode(mode), WithIV(nonce))
+			if !assert.Nil(t, err, "%s %s", alg, mode) {
+				continue
+			}
+			encrypted, err := c.Encrypt(plain)
+			assert.Nil(t, err, "%s %s", alg, mode)
+			decrypted, err := c.Decrypt(encrypted)
+			assert.Nil(t, err, "%s %s", alg, mode)
+			assert.Equal(t, plain, decrypted, "%s %s", alg, mode)
+
+			if nonce == nil && mode != ECB {
+				// 每次加密使用新的IV, 相同明文的密文不同
+				again, err := c.Encrypt(plain)
+				assert.Nil(t, err, "%s %s", alg, mode)
+				assert.NotEqual(t, encrypted, again, "%s %s", alg, mode)
+			}
+		}
+	}
+}
+
+func TestCipherOptions(t *testing.T) {
+	key := cipherKeys[AES]
+
+	_, err := NewCipher("RC4", key)
+	assert.NotNil(t, err)
+	_, err = NewCipher(AES, key, WithMode("XTS"), WithIV(key))
+	assert.NotNil(t, err)
+	_, err = NewCipher(AES, key, WithPadding("ISO10126"), WithIV(key))
+	assert.NotNil(t, err)
+	_, err = NewCipher(AES, key, WithMode(CBC))
+	assert.NotNil(t, err)
+	_, err = NewCipher(AES, key, WithMode(CTR), WithPadding(PKCS7))
+	assert.NotNil(t, err)
+	for _, mode := range []Mode{CTR, CFB, OFB} {
+		_, err = NewCipher(AES, key, WithMode(mode), WithIV(key))
+		assert.True(t, errors.Is(err, ErrInvalidIV), "%s", mode)
+	}
+	_, err = NewCipher(AES, key, WithMode(GCM), WithIV(key[:GCMNonceSize]))
+	assert.True(t, errors.Is(err, ErrInvalidIV))
+
+	c, err := NewCipher(AES, key, WithMode(ECB), WithPadding(NONE))
+	assert.Nil(t, err)
+	_, err = c.Encrypt([]byte("not full block"))
+	assert.NotNil(t, err)
+	_, err = c.Decrypt([]byte("not full block"))
+	assert.NotNil(t, err)
+}
+
+func TestCipherConcurrent(t *testing.T) {
+	c, err := NewCipher(SM4, cipherKeys[SM4], WithIV(cipherKeys[SM4]))
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			plain := bytes.Repeat([]byte{byte(i)}, i*7)
+			for j := 0; j < 100; j++ {
+				encrypted, err := c.Encrypt(plain)
+				assert.Nil(t, err)
+				decrypted, err := c.Decrypt(encrypted)
+				assert.Nil(t, err)
+				assert.Equal(t, plain, decrypted)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCipherErrors(t *testing.T) {
+	plain := []byte("typed errors")
+	key := cipherKeys[SM4]
+
+	// sm4 with NONE padding used to encrypt nothing
+	encrypted, err := SM4Encrypt(bytes.Repeat(plain[:8], 4), key, key, CBC, NONE)
+	assert.Nil(t, err)
+	assert.Equal(t, 32, len(encrypted))
+
+	// unknown mode and padding no longer fall back to defaults
+	_, err = DESEncrypt(plain, cipherKeys[DES], "", ZERO)
+	assert.True(t, errors.Is(err, ErrInvalidMode))
+	_, err = DESEncrypt(plain, cipherKeys[DES], CBC, "ISO10126")
+	assert.True(t, errors.Is(err, ErrInvalidPadding))
+	_, err = SM4Decrypt(encrypted, key, key, ECB, "ISO10126")
+	assert.True(t, errors.Is(err, ErrInvalidPadding))
+	// the wrappers need an explicit padding, only NewCipher has a default
+	_, err = AESEncrypt(plain, key, key, CBC, "")
+	assert.True(t, errors.Is(err, ErrInvalidPadding))
+	_, err = DESEncrypt(plain, cipherKeys[DES], CBC, "")
+	assert.True(t, errors.Is(err, ErrInvalidPadding))
+	_, err = DES3Encrypt(plain, cipherKeys[DES3], cipherKeys[DES], ECB, "")
+	assert.True(t, errors.Is(err, ErrInvalidPadding))
+	_, err = SM4Decrypt(encrypted, key, key, CBC, "")
+	assert.True(t, errors.Is(err, ErrInvalidPadding))
+	_, err = NewCipher("RC4", key)
+	assert.True(t, errors.Is(err, ErrInvalidAlgorithm))
+	_, err = NewCipher(DES, cipherKeys[DES], WithMode(GCM))
+	assert.True(t, errors.Is(err, ErrInvalidMode))
+
+	// wrong key and iv length
+	_, err = AESEncrypt(plain, key[:10], key, CBC, PKCS7)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = SM4Encrypt(plain, key, nil, CBC, PKCS7)
+	assert.True(t, errors.Is(err, ErrInvalidIV))
+	_, err = DES3Encrypt(plain, cipherKeys[DES3], key, CBC, PKCS7)
+	assert.True(t, errors.Is(err, ErrInvalidIV))
+
+	// broken ciphertext
+	_, err = AESDecrypt(encrypted[:31], key, key, CBC, PKCS7)
+	assert.True(t, errors.Is(err, ErrNotFullBlocks))
+	_, err = AESDecrypt(nil, key, key, CBC, PKCS7)
+	assert.True(t, errors.Is(err, ErrInvalidPadding))
+	_, err = AESDecrypt(encrypted, key, key, CBC, PKCS7)
+	assert.True(t, errors.Is(err, ErrInvalidPadding))
+}
@@ -0,0 +1,352 @@
+package crypt
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// GCM流式加密时每段明文的长度
+	streamSegmentSize = 64 * 1024
+	// GCM流式加密的nonce前缀长度, nonce = 前缀(7) | 段序号(4) | 结束标记(1)
+	streamNoncePrefixSize = 7
+)
+
+var errWriterClosed = errors.New("encrypt writer already closed")
+
+// NewEncryptWriter 创建加密Writer, 写入的数据加密后写到w
+// 数据结束后必须调用Close, CBC/ECB模式在Close时才写入填充, Close不会关闭w
+//
+// GCM模式将数据按64KB分段加密, 每段都带有tag, 可以检测截断和重排
+// 每个流随机生成7字节的nonce前缀写在最前面, 不能使用固定的IV
+//
+// CTR/CFB/OFB模式每个流随机生成IV写在最前面
+//
+// 其他模式的输出与Cipher.Encrypt一致
+func NewEncryptWriter(w io.Writer, c *Cipher) (io.WriteCloser, error) {
+	switch c.mode {
+	case GCM:
+		return newGCMWriter(w, c, nil)
+	case CTR, CFB, OFB:
+		iv := c.iv
+		if len(iv) == 0 {
+			iv = make([]byte, c.block.BlockSize())
+			if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(iv); err != nil {
+				return nil, err
+			}
+		}
+		stream, err := newStream(c.block, iv, c.mode, true)
+		if err != nil {
+			return nil, err
+		}
+		return &streamWriter{w: cipher.StreamWriter{S: stream, W: w}}, nil
+	default:
+		return &blockWriter{w: w, c: c, mode: c.blockMode(true)}, nil
+	}
+}
+
+// NewDecryptReader 创建解密Reader, 从r读取密文并返回解密后的数据, 与NewEncryptWriter对应
+// GCM模式下每段数据校验通过后才会返回, 校验失败或数据被截断时返回ErrAuthentication
+func NewDecryptReader(r io.Reader, c *Cipher) (io.Reader, error) {
+	switch c.mode {
+	case GCM:
+		return newGCMReader(r, c, nil)
+	case CTR, CFB, OFB:
+		iv := c.iv
+		if len(iv) == 0 {
+			iv = make([]byte, c.block.BlockSize())
+			if _, err := io.ReadFull(r, iv); err != nil {
+				return nil, fmt.Errorf("%w: missing iv prefix in mode %s", ErrInvalidIV, c.mode)
+			}
+		}
+		stream, err := newStream(c.block, iv, c.mode, false)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.StreamReader{S: stream, R: r}, nil
+	default:
+		return &blockReader{r: r, c: c, mode: c.blockMode(false)}, nil
+	}
+}
+
+// streamWriter 流模式加密, Close时不关闭下层的Writer
+type streamWriter struct {
+	w cipher.StreamWriter
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	return s.w.Write(p)
+}
+
+func (s *streamWriter) Close() error {
+	return nil
+}
+
+// blockWriter CBC/ECB模式加密, 不足一个分组的数据缓存到Close时填充
+type blockWriter struct {
+	w      io.Writer
+	c      *Cipher
+	mode   cipher.BlockMode
+	buf    []byte
+	closed bool
+}
+
+func (b *blockWriter) Write(p []byte) (int, error) {
+	if b.closed {
+		return 0, errWriterClosed
+	}
+	b.buf = append(b.buf, p...)
+	n := len(b.buf) - len(b.buf)%b.mode.BlockSize()
+	if n > 0 {
+		out := make([]byte, n)
+		b.mode.CryptBlocks(out, b.buf[:n])
+		b.buf = append(b.buf[:0], b.buf[n:]...)
+		if _, err := b.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (b *blockWriter) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	data := pad(b.buf, b.mode.BlockSize(), b.c.padding)
+	if len(data)%b.mode.BlockSize() != 0 {
+		return fmt.Errorf("%w: padding %s", ErrNotFullBlocks, b.c.padding)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	out := make([]byte, len(data))
+	b.mode.CryptBlocks(out, data)
+	_, err := b.w.Write(out)
+	return err
+}
+
+// blockReader CBC/ECB模式解密, 始终保留最后一个分组, 读到EOF时再去除填充
+type blockReader struct {
+	r       io.Reader
+	c       *Cipher
+	mode    cipher.BlockMode
+	pending []byte
+	out     []byte
+	eof     bool
+	final   bool // 是否已解密最后一个分组
+	buf     [4096]byte
+}
+
+func (b *blockReader) Read(p []byte) (int, error) {
+	for len(b.out) == 0 {
+		if b.eof {
+			// 空密文没有填充分组
+			if !b.final && (b.c.padding == PKCS5 || b.c.padding == PKCS7) {
+				return 0, fmt.Errorf("%w: missing padding block", ErrInvalidPadding)
+			}
+			return 0, io.EOF
+		}
+		n, err := b.r.Read(b.buf[:])
+		b.pending = append(b.pending, b.buf[:n]...)
+		if err == io.EOF {
+			b.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+
+		blockSize := b.mode.BlockSize()
+		keep := len(b.pending) % blockSize
+		if b.eof {
+			if keep != 0 {
+				return 0, ErrNotFullBlocks
+			}
+		} else if keep == 0 {
+			keep = blockSize
+		}
+		if n = len(b.pending) - keep; n <= 0 {
+			continue
+		}
+		out := make([]byte, n)
+		b.mode.CryptBlocks(out, b.pending[:n])
+		b.pending = append(b.pending[:0], b.pending[n:]...)
+		if b.eof {
+			b.final = true
+			if out, err = unpad(out, blockSize, b.c.padding); err != nil {
+				return 0, err
+			}
+		}
+		b.out = out
+	}
+	n := copy(p, b.out)
+	b.out = b.out[n:]
+	return n, nil
+}
+
+// streamNonce 生成GCM流式加密每段使用的nonce
+func streamNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, GCMNonceSize)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefixSize:], counter)
+	if last {
+		nonce[GCMNonceSize-1] = 1
+	}
+	return nonce
+}
+
+// checkStreamGCM 检查Cipher能否用于GCM流式加密, 固定的IV会导致不同的流重复使用nonce
+func checkStreamGCM(c *Cipher) error {
+	if len(c.iv) > 0 {
+		return fmt.Errorf("%w: fixed nonce is not allowed in stream GCM mode", ErrInvalidIV)
+	}
+	if c.aead.NonceSize() != GCMNonceSize {
+		return fmt.Errorf("%w: nonce length must be %d in stream GCM mode", ErrInvalidIV, GCMNonceSize)
+	}
+	return nil
+}
+
+// gcmWriter GCM模式分段加密
+type gcmWriter struct {
+	w       io.Writer
+	c       *Cipher
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	closed  bool
+}
+
+// newGCMWriter prefix为空时随机生成并写入w, 否则由调用方保存(如文件头中的前缀)
+func newGCMWriter(w io.Writer, c *Cipher, prefix []byte) (io.WriteCloser, error) {
+	if err := checkStreamGCM(c); err != nil {
+		return nil, err
+	}
+	if prefix == nil {
+		prefix = make([]byte, streamNoncePrefixSize)
+		if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(prefix); err != nil {
+			return nil, err
+		}
+	}
+	return &gcmWriter{w: w, c: c, prefix: prefix, buf: make([]byte, 0, streamSegmentSize)}, nil
+}
+
+// seal 加密并写入一段数据
+func (g *gcmWriter) seal(last bool) error {
+	if g.counter == ^uint32(0) {
+		return errors.New("too many segments in stream GCM mode")
+	}
+	out := g.c.aead.Seal(nil, streamNonce(g.prefix, g.counter, last), g.buf, g.c.aad)
+	g.counter++
+	g.buf = g.buf[:0]
+	_, err := g.w.Write(out)
+	return err
+}
+
+func (g *gcmWriter) Write(p []byte) (int, error) {
+	if g.closed {
+		return 0, errWriterClosed
+	}
+	total := len(p)
+	for len(p) > 0 {
+		// 缓冲区满且还有数据时才加密, 保证最后一段在Close时带结束标记
+		if len(g.buf) == streamSegmentSize {
+			if err := g.seal(false); err != nil {
+				return total - len(p), err
+			}
+		}
+		n := copy(g.buf[len(g.buf):streamSegmentSize], p)
+		g.buf = g.buf[:len(g.buf)+n]
+		p = p[n:]
+	}
+	return total, nil
+}
+
+func (g *gcmWriter) Close() error {
+	if g.closed {
+		return nil
+	}
+	g.closed = true
+	return g.seal(true)
+}
+
+// gcmReader GCM模式分段解密
+type gcmReader struct {
+	r       *bufio.Reader
+	c       *Cipher
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	out     []byte
+	done    bool
+}
+
+// newGCMReader prefix为空时从r读取, 与newGCMWriter对应
+func newGCMReader(r io.Reader, c *Cipher, prefix []byte) (io.Reader, error) {
+	if err := checkStreamGCM(c); err != nil {
+		return nil, err
+	}
+	if prefix == nil {
+		prefix = make([]byte, streamNoncePrefixSize)
+		if _, err := io.ReadFull(r, prefix); err != nil {
+			return nil, ErrAuthentication
+		}
+	}
+	return &gcmReader{
+		r:      bufio.NewReader(r),
+		c:      c,
+		prefix: prefix,
+		buf:    make([]byte, streamSegmentSize+c.aead.Overhead()),
+	}, nil
+}
+
+// open 读取并解密一段数据
+func (g *gcmReader) open() error {
+	n, err := io.ReadFull(g.r, g.buf)
+	last := false
+	switch err {
+	case nil:
+		// 刚好读满一段时, 通过后续是否还有数据判断是否为最后一段
+		if _, err = g.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	case io.ErrUnexpectedEOF, io.EOF:
+		last = true
+	default:
+		return err
+	}
+
+	out, err := g.c.aead.Open(g.buf[:0], streamNonce(g.prefix, g.counter, last), g.buf[:n], g.c.aad)
+	if err != nil {
+		return ErrAuthentication
+	}
+	g.counter++
+	g.out = out
+	g.done = last
+	return nil
+}
+
+func (g *gcmReader) Read(p []byte) (int, error) {
+	for len(g.out) == 0 {
+		if g.done {
+			return 0, io.EOF
+		}
+		if err := g.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, g.out)
+	g.out = g.out[n:]
+	return n, nil
+}
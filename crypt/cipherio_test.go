@@ -0,0 +1,140 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encryptStream(t *testing.T, c *Cipher, plain []byte, chunk int) []byte {
+	out := bytes.NewBuffer(nil)
+	w, err := NewEncryptWriter(out, c)
+	assert.Nil(t, err)
+	for len(plain) > 0 {
+		n := chunk
+		if n > len(plain) {
+			n = len(plain)
+		}
+		_, err = w.Write(plain[:n])
+		assert.Nil(t, err)
+		plain = plain[n:]
+	}
+	assert.Nil(t, w.Close())
+	return out.Bytes()
+}
+
+func decryptStream(c *Cipher, encrypted []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(encrypted), c)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestCipherStream(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	for _, size := range []int{0, 1, 15, 16, 1000, streamSegmentSize, streamSegmentSize + 1, 3*streamSegmentSize + 7} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+
+		for _, opts := range [][]CipherOption{
+			{WithMode(CBC), WithPadding(PKCS7), WithIV(key)},
+			{WithMode(ECB), WithPadding(PKCS5)},
+			{WithMode(CTR), WithPadding(NONE), WithIV(key)},
+			{WithMode(CFB), WithPadding(NONE), WithIV(key)},
+		} {
+			// 固定IV时流式输出与Encrypt一致
+			c, err := newCipher(AES, key, opts...)
+			assert.Nil(t, err)
+			encrypted := encryptStream(t, c, plain, 333)
+			expect, err := c.Encrypt(plain)
+			assert.Nil(t, err)
+			assert.True(t, bytes.Equal(expect, encrypted), "%s %d", c.Mode(), size)
+
+			decrypted, err := decryptStream(c, encrypted)
+			assert.Nil(t, err, "%s %d", c.Mode(), size)
+			assert.Equal(t, len(plain), len(decrypted), "%s %d", c.Mode(), size)
+			assert.True(t, bytes.Equal(plain, decrypted), "%s %d", c.Mode(), size)
+		}
+
+		for _, mode := range []Mode{CTR, CFB, OFB} {
+			c, err := NewCipher(AES, key, WithMode(mode))
+			assert.Nil(t, err)
+			encrypted := encryptStream(t, c, plain, 333)
+			assert.Equal(t, size+c.BlockSize(), len(encrypted), "%s %d", mode, size)
+			decrypted, err := decryptStream(c, encrypted)
+			assert.Nil(t, err, "%s %d", mode, size)
+			assert.True(t, bytes.Equal(plain, decrypted), "%s %d", mode, size)
+			decrypted, err = c.Decrypt(encrypted)
+			assert.Nil(t, err, "%s %d", mode, size)
+			assert.True(t, bytes.Equal(plain, decrypted), "%s %d", mode, size)
+		}
+
+		for _, alg := range []Algorithm{AES, SM4} {
+			c, err := NewCipher(alg, key, WithMode(GCM), WithAAD([]byte("export")))
+			assert.Nil(t, err)
+			encrypted := encryptStream(t, c, plain, 40000)
+			decrypted, err := decryptStream(c, encrypted)
+			assert.Nil(t, err, "%s %d", alg, size)
+			assert.True(t, bytes.Equal(plain, decrypted), "%s %d", alg, size)
+		}
+	}
+}
+
+func TestGCMStreamTamper(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	c, err := NewCipher(AES, key, WithMode(GCM))
+	assert.Nil(t, err)
+
+	plain := make([]byte, 2*streamSegmentSize+100)
+	rand.Read(plain)
+	encrypted := encryptStream(t, c, plain, len(plain))
+	segment := streamSegmentSize + 16
+
+	// drop the last segment
+	_, err = decryptStream(c, encrypted[:streamNoncePrefixSize+2*segment])
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	// swap the first two segments
+	swapped := append([]byte(nil), encrypted[:streamNoncePrefixSize]...)
+	swapped = append(swapped, encrypted[streamNoncePrefixSize+segment:streamNoncePrefixSize+2*segment]...)
+	swapped = append(swapped, encrypted[streamNoncePrefixSize:streamNoncePrefixSize+segment]...)
+	swapped = append(swapped, encrypted[streamNoncePrefixSize+2*segment:]...)
+	_, err = decryptStream(c, swapped)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	// flip one byte
+	encrypted[len(encrypted)/2] ^= 1
+	_, err = decryptStream(c, encrypted)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+}
+
+func TestGCMStreamFixedNonce(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	c, err := newCipher(AES, key, WithMode(GCM), WithPadding(NONE), WithIV(key[:GCMNonceSize]))
+	assert.Nil(t, err)
+	_, err = NewEncryptWriter(bytes.NewBuffer(nil), c)
+	assert.True(t, errors.Is(err, ErrInvalidIV))
+	_, err = NewDecryptReader(bytes.NewReader(nil), c)
+	assert.True(t, errors.Is(err, ErrInvalidIV))
+}
+
+func TestBlockStreamEmpty(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	for _, padding := range []Padding{PKCS5, PKCS7, ZERO, NONE} {
+		c, err := NewCipher(AES, key, WithMode(CBC), WithPadding(padding), WithIV(key))
+		assert.Nil(t, err)
+		decrypted, err := decryptStream(c, nil)
+		if padding == PKCS5 || padding == PKCS7 {
+			// 空密文缺少填充分组
+			assert.True(t, errors.Is(err, ErrInvalidPadding), "%s", padding)
+		} else {
+			assert.Nil(t, err, "%s", padding)
+			assert.Equal(t, 0, len(decrypted), "%s", padding)
+		}
+	}
+}
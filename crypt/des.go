@@ -1,138 +1,48 @@
 package crypt
 
-import (
-	"crypto/cipher"
-	"crypto/des"
-)
+import "fmt"
 
 // encrypt data with des
+// the key is used as iv in CBC mode, stream modes (CTR/CFB/OFB) are not supported
+// because a fixed iv makes every message use the same keystream
 func DESEncrypt(origData, key []byte, mode Mode, padding Padding) ([]byte, error) {
-	block, err := des.NewCipher(key)
+	if isStreamMode(mode) {
+		return nil, fmt.Errorf("%w: %s needs an iv, use NewCipher with WithIV", ErrInvalidMode, mode)
+	}
+	c, err := newCipher(DES, key, WithMode(mode), WithPadding(padding), WithIV(key))
 	if err != nil {
 		return nil, err
 	}
-	switch padding {
-	case PKCS5:
-		origData = PKCS5Padding(origData, block.BlockSize())
-	case PKCS7:
-		origData = PKCS7Padding(origData, block.BlockSize())
-	case ZERO:
-		origData = ZeroPadding(origData, block.BlockSize())
-	case NONE:
-		//
-	default:
-		origData = ZeroPadding(origData, block.BlockSize())
-	}
-	var blockMode cipher.BlockMode
-	switch mode {
-	case CBC:
-		blockMode = cipher.NewCBCEncrypter(block, key)
-	case ECB:
-		blockMode = NewECBEncrypter(block)
-	default:
-		blockMode = cipher.NewCBCEncrypter(block, key)
-	}
-
-	crypted := make([]byte, len(origData))
-
-	blockMode.CryptBlocks(crypted, origData)
-	return crypted, nil
+	return c.Encrypt(origData)
 }
 
 // descrypt data with des
+// the key is used as iv in CBC mode, stream modes (CTR/CFB/OFB) are not supported
 func DESDecrypt(crypted, key []byte, mode Mode, padding Padding) ([]byte, error) {
-	block, err := des.NewCipher(key)
+	if isStreamMode(mode) {
+		return nil, fmt.Errorf("%w: %s needs an iv, use NewCipher with WithIV", ErrInvalidMode, mode)
+	}
+	c, err := newCipher(DES, key, WithMode(mode), WithPadding(padding), WithIV(key))
 	if err != nil {
 		return nil, err
 	}
-	var blockMode cipher.BlockMode
-	switch mode {
-	case CBC:
-		blockMode = cipher.NewCBCDecrypter(block, key)
-	case ECB:
-		blockMode = NewECBDecrypter(block)
-	default:
-		blockMode = cipher.NewCBCDecrypter(block, key)
-	}
-	origData := make([]byte, len(crypted))
-
-	blockMode.CryptBlocks(origData, crypted)
-	switch padding {
-	case PKCS5:
-		origData = PKCS5UnPadding(origData)
-	case ZERO:
-		origData = ZeroUnPadding(origData)
-	case NONE:
-		//
-	default:
-		origData = ZeroUnPadding(origData)
-	}
-	return origData, nil
+	return c.Decrypt(crypted)
 }
 
 // encrypt data with 3des
 func DES3Encrypt(origData, key, keyiv []byte, mode Mode, padding Padding) ([]byte, error) {
-	block, err := des.NewTripleDESCipher(key)
+	c, err := newCipher(DES3, key, WithMode(mode), WithPadding(padding), WithIV(keyiv))
 	if err != nil {
 		return nil, err
 	}
-	switch padding {
-	case PKCS5:
-		origData = PKCS5Padding(origData, block.BlockSize())
-	case PKCS7:
-		origData = PKCS7Padding(origData, block.BlockSize())
-	case ZERO:
-		origData = ZeroPadding(origData, block.BlockSize())
-	case NONE:
-		//
-	default:
-		origData = ZeroPadding(origData, block.BlockSize())
-	}
-	var blockMode cipher.BlockMode
-	switch mode {
-	case CBC:
-		blockMode = cipher.NewCBCEncrypter(block, keyiv)
-	case ECB:
-		blockMode = NewECBEncrypter(block)
-	default:
-		blockMode = cipher.NewCBCEncrypter(block, keyiv)
-	}
-	crypted := make([]byte, len(origData))
-	blockMode.CryptBlocks(crypted, origData)
-	return crypted, nil
+	return c.Encrypt(origData)
 }
 
 // descrypt data with 3des
 func DES3Decrypt(crypted, key, keyiv []byte, mode Mode, padding Padding) ([]byte, error) {
-	block, err := des.NewTripleDESCipher(key)
+	c, err := newCipher(DES3, key, WithMode(mode), WithPadding(padding), WithIV(keyiv))
 	if err != nil {
 		return nil, err
 	}
-	var blockMode cipher.BlockMode
-	switch mode {
-	case CBC:
-		blockMode = cipher.NewCBCDecrypter(block, keyiv)
-	case ECB:
-		blockMode = NewECBDecrypter(block)
-	default:
-		blockMode = cipher.NewCBCDecrypter(block, keyiv)
-	}
-	origData := make([]byte, len(crypted))
-
-	blockMode.CryptBlocks(origData, crypted)
-
-	switch padding {
-	case PKCS5:
-		origData = PKCS5UnPadding(origData)
-	case PKCS7:
-		origData = PKCS7UnPadding(origData)
-	case ZERO:
-		origData = ZeroUnPadding(origData)
-	case NONE:
-		//
-	default:
-		origData = ZeroUnPadding(origData)
-	}
-
-	return origData, nil
+	return c.Decrypt(crypted)
 }
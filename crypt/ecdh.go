@@ -0,0 +1,70 @@
+package crypt
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+)
+
+// generate ecdh private key on the given curve, such as ecdh.X25519() or ecdh.P256()
+func GenerateECDHKey(curve ecdh.Curve) (*ecdh.PrivateKey, error) {
+	return curve.GenerateKey(rand.Reader)
+}
+
+// 读取ecdh私钥, 支持PKCS#8(X25519, P-256等)和SEC1("EC PRIVATE KEY")格式
+// 私钥可以是PEM, 也可以是没有前缀后缀的单行base64, ecdsa私钥会转换为对应曲线的ecdh私钥
+func ParseECDHPrivateKey(in []byte) (*ecdh.PrivateKey, error) {
+	der, err := decodeKey(in)
+	if err != nil {
+		return nil, err
+	}
+	var pri any
+	if pri, err = x509.ParseECPrivateKey(der); err != nil {
+		if pri, err = x509.ParsePKCS8PrivateKey(der); err != nil {
+			return nil, err
+		}
+	}
+	switch k := pri.(type) {
+	case *ecdh.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k.ECDH()
+	default:
+		return nil, keyTypeError("*ecdh.PrivateKey", pri)
+	}
+}
+
+// 读取ecdh公钥(PKIX), ecdsa公钥会转换为对应曲线的ecdh公钥
+func ParseECDHPublicKey(in []byte) (*ecdh.PublicKey, error) {
+	pub, err := ParsePKIXPublicKey(in)
+	if err != nil {
+		return nil, err
+	}
+	switch k := pub.(type) {
+	case *ecdh.PublicKey:
+		return k, nil
+	case *ecdsa.PublicKey:
+		return k.ECDH()
+	default:
+		return nil, keyTypeError("*ecdh.PublicKey", pub)
+	}
+}
+
+// ECDH 计算共享密钥, 共享密钥不能直接作为对称密钥, 应使用ECDHSessionKey或HKDF派生
+// X25519的对方公钥为小阶点时返回错误
+func ECDH(priv *ecdh.PrivateKey, pub *ecdh.PublicKey) ([]byte, error) {
+	return priv.ECDH(pub)
+}
+
+// ECDHSessionKey 使用ECDH计算共享密钥, 再使用HKDF派生keyLen长度的会话密钥
+// 双方的salt和info必须一致, info用于区分用途, 建议包含双方的公钥或会话标识
+//
+//	key, err := ECDHSessionKey(SHA256, serverKey, devicePub, nil, []byte("device-channel"), 32)
+func ECDHSessionKey(h Hash, priv *ecdh.PrivateKey, pub *ecdh.PublicKey, salt, info []byte, keyLen int) ([]byte, error) {
+	secret, err := ECDH(priv, pub)
+	if err != nil {
+		return nil, err
+	}
+	return HKDF(h, secret, salt, info, keyLen)
+}
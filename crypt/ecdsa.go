@@ -0,0 +1,124 @@
+package crypt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+)
+
+// ecdsa signature format
+type SigFormat string
+
+const (
+	//ASN.1 DER SEQUENCE{r, s}, as java SHA256withECDSA and openssl output
+	ASN1 SigFormat = "ASN1"
+	//raw r|s, each left padded to the curve size, as IEEE P1363 and JWS ES256
+	P1363 SigFormat = "P1363"
+)
+
+// generate ecdsa private key on the given curve, such as elliptic.P256()
+func GenerateECDSAKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(curve, rand.Reader)
+}
+
+// 读取ecdsa私钥, 支持PKCS#8和SEC1("EC PRIVATE KEY")格式
+// 私钥可以是PEM, 也可以是没有前缀后缀的单行base64, 不是ecdsa私钥时返回*KeyTypeError
+func ParseECPrivateKey(in []byte) (*ecdsa.PrivateKey, error) {
+	der, err := decodeKey(in)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	pri, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pri.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, keyTypeError("*ecdsa.PrivateKey", pri)
+	}
+	return key, nil
+}
+
+// 读取ecdsa公钥(PKIX), 不是ecdsa公钥时返回*KeyTypeError
+func ParseECPublicKey(in []byte) (*ecdsa.PublicKey, error) {
+	pub, err := ParsePKIXPublicKey(in)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, keyTypeError("*ecdsa.PublicKey", pub)
+	}
+	return key, nil
+}
+
+// marshal ecdsa private key to SEC1, type "EC PRIVATE KEY" in pem format
+func MarshalECPrivateKey(key *ecdsa.PrivateKey, format KeyFormat) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return encodeKey(der, "EC PRIVATE KEY", format)
+}
+
+// sign data with ecdsa private key, the digest of data is calculated with h
+func SignECDSA(key *ecdsa.PrivateKey, data []byte, h Hash, format SigFormat) ([]byte, error) {
+	digest, err := signDigest(h, data)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case ASN1:
+		return ecdsa.SignASN1(rand.Reader, key, digest)
+	case P1363:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		if err != nil {
+			return nil, err
+		}
+		size := curveSize(key.Curve)
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	default:
+		return nil, fmt.Errorf("%w: signature format %s", ErrInvalidFormat, format)
+	}
+}
+
+// verify ecdsa signature, returns ErrVerification if signature is invalid
+func VerifyECDSA(key *ecdsa.PublicKey, data, sig []byte, h Hash, format SigFormat) error {
+	digest, err := signDigest(h, data)
+	if err != nil {
+		return err
+	}
+	ok := false
+	switch format {
+	case ASN1:
+		ok = ecdsa.VerifyASN1(key, digest, sig)
+	case P1363:
+		size := curveSize(key.Curve)
+		if len(sig) != 2*size {
+			return ErrVerification
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		ok = ecdsa.Verify(key, digest, r, s)
+	default:
+		return fmt.Errorf("%w: signature format %s", ErrInvalidFormat, format)
+	}
+	if !ok {
+		return ErrVerification
+	}
+	return nil
+}
+
+// curveSize 返回曲线阶的字节长度
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
@@ -0,0 +1,143 @@
+package crypt
+
+import (
+	"crypto/ecdh"
+	"fmt"
+)
+
+// ECIES格式版本
+const eciesVersion = 1
+
+// ECIES中密钥协商使用的曲线
+const (
+	eciesX25519 = 1 // X25519, 临时公钥32字节
+	eciesP256   = 2 // P-256, 临时公钥为65字节未压缩点
+)
+
+// ECIES中加密数据的算法
+const (
+	eciesAESGCM = 1 // AES-256-GCM
+	eciesSM4GCM = 2 // SM4-GCM
+)
+
+// ECIES头部长度: 版本(1) | 曲线(1) | 数据算法(1)
+const eciesHeaderSize = 3
+
+// eciesInfo HKDF派生数据密钥时使用的info前缀
+var eciesInfo = []byte("zk-util ecies")
+
+// ECIESEncrypt 使用接收方的X25519或P-256公钥加密数据
+// 随机生成临时密钥对, 与接收方公钥协商共享密钥, 使用HKDF-SHA256派生数据密钥, 再使用AES-256-GCM或SM4-GCM加密
+// 参数:
+//   - pub: 接收方公钥, 曲线为ecdh.X25519()或ecdh.P256()
+//   - data: 需要加密的原始数据
+//   - alg: 数据加密算法, AES或SM4
+//
+// 返回:
+//   - 密文: 版本(1) | 曲线(1) | 数据算法(1) | 临时公钥(32或65) | nonce(12) | 密文 | tag(16)
+//     HKDF的info为固定前缀 | 头部 | 临时公钥 | 接收方公钥, 头部和临时公钥作为GCM的附加数据
+//   - 错误信息(如果有)
+func ECIESEncrypt(pub *ecdh.PublicKey, data []byte, alg Algorithm) ([]byte, error) {
+	curve, err := eciesCurveID(pub.Curve())
+	if err != nil {
+		return nil, err
+	}
+	dataAlg, keySize, err := eciesDataAlg(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := GenerateECDHKey(pub.Curve())
+	if err != nil {
+		return nil, err
+	}
+	header := append([]byte{eciesVersion, curve, dataAlg}, ephemeral.PublicKey().Bytes()...)
+	key, err := eciesKey(ephemeral, pub, pub, header, keySize)
+	if err != nil {
+		return nil, err
+	}
+	out, err := gcmCrypt(alg, data, key, nil, header, true)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, out...), nil
+}
+
+// ECIESDecrypt 使用接收方私钥解密ECIESEncrypt的输出
+// 格式错误时返回ErrInvalidFormat, 密钥不匹配或数据被篡改时返回ErrAuthentication
+func ECIESDecrypt(priv *ecdh.PrivateKey, data []byte) ([]byte, error) {
+	if len(data) < eciesHeaderSize || data[0] != eciesVersion {
+		return nil, fmt.Errorf("%w: unsupported ecies version", ErrInvalidFormat)
+	}
+	var curve ecdh.Curve
+	switch data[1] {
+	case eciesX25519:
+		curve = ecdh.X25519()
+	case eciesP256:
+		curve = ecdh.P256()
+	default:
+		return nil, fmt.Errorf("%w: unknown ecies curve %d", ErrInvalidFormat, data[1])
+	}
+	if priv.Curve() != curve {
+		return nil, fmt.Errorf("%w: ecies data is encrypted on another curve", ErrInvalidKey)
+	}
+	var alg Algorithm
+	switch data[2] {
+	case eciesAESGCM:
+		alg = AES
+	case eciesSM4GCM:
+		alg = SM4
+	default:
+		return nil, fmt.Errorf("%w: unknown ecies data algorithm %d", ErrInvalidFormat, data[2])
+	}
+	_, keySize, _ := eciesDataAlg(alg)
+
+	pubSize := 32
+	if curve == ecdh.P256() {
+		pubSize = 65
+	}
+	if len(data) < eciesHeaderSize+pubSize {
+		return nil, fmt.Errorf("%w: ecies data too short", ErrInvalidFormat)
+	}
+	header := data[:eciesHeaderSize+pubSize]
+	ephemeral, err := curve.NewPublicKey(header[eciesHeaderSize:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	key, err := eciesKey(priv, ephemeral, priv.PublicKey(), header, keySize)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return gcmCrypt(alg, data[len(header):], key, nil, header, false)
+}
+
+// eciesKey 协商共享密钥并派生数据密钥, recipient为接收方公钥
+func eciesKey(priv *ecdh.PrivateKey, peer, recipient *ecdh.PublicKey, header []byte, keySize int) ([]byte, error) {
+	info := make([]byte, 0, len(eciesInfo)+len(header)+len(recipient.Bytes()))
+	info = append(append(append(info, eciesInfo...), header...), recipient.Bytes()...)
+	return ECDHSessionKey(SHA256, priv, peer, nil, info, keySize)
+}
+
+// eciesCurveID 返回曲线在ECIES格式中的编号
+func eciesCurveID(curve ecdh.Curve) (byte, error) {
+	switch curve {
+	case ecdh.X25519():
+		return eciesX25519, nil
+	case ecdh.P256():
+		return eciesP256, nil
+	default:
+		return 0, fmt.Errorf("%w: ecies curve %s", ErrInvalidKey, curve)
+	}
+}
+
+// eciesDataAlg 返回数据算法在ECIES格式中的编号和密钥长度
+func eciesDataAlg(alg Algorithm) (byte, int, error) {
+	switch alg {
+	case AES:
+		return eciesAESGCM, 32, nil
+	case SM4:
+		return eciesSM4GCM, 16, nil
+	default:
+		return 0, 0, fmt.Errorf("%w: ecies data algorithm %s", ErrInvalidAlgorithm, alg)
+	}
+}
@@ -0,0 +1,68 @@
+package crypt
+
+import (
+	"crypto/ecdh"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECIES(t *testing.T) {
+	plain := []byte("telemetry from device 0001")
+	for _, curve := range []ecdh.Curve{ecdh.X25519(), ecdh.P256()} {
+		key, err := GenerateECDHKey(curve)
+		assert.Nil(t, err)
+		pubSize := len(key.PublicKey().Bytes())
+
+		for _, alg := range []Algorithm{AES, SM4} {
+			encrypted, err := ECIESEncrypt(key.PublicKey(), plain, alg)
+			if !assert.Nil(t, err, "%s %s", curve, alg) {
+				continue
+			}
+			assert.Equal(t, eciesHeaderSize+pubSize+GCMNonceSize+len(plain)+16, len(encrypted))
+			assert.Equal(t, byte(eciesVersion), encrypted[0])
+
+			decrypted, err := ECIESDecrypt(key, encrypted)
+			assert.Nil(t, err, "%s %s", curve, alg)
+			assert.Equal(t, plain, decrypted, "%s %s", curve, alg)
+
+			// ephemeral key is random
+			again, _ := ECIESEncrypt(key.PublicKey(), plain, alg)
+			assert.NotEqual(t, encrypted, again)
+
+			// any modification is detected
+			for _, i := range []int{2, eciesHeaderSize + 1, len(encrypted) - 1} {
+				_, err = ECIESDecrypt(key, flipByte(encrypted, i))
+				assert.NotNil(t, err, "%s %s %d", curve, alg, i)
+			}
+		}
+	}
+}
+
+func TestECIESErrors(t *testing.T) {
+	key, _ := GenerateECDHKey(ecdh.X25519())
+	other, _ := GenerateECDHKey(ecdh.X25519())
+	p256, _ := GenerateECDHKey(ecdh.P256())
+	encrypted, err := ECIESEncrypt(key.PublicKey(), []byte("data"), AES)
+	assert.Nil(t, err)
+
+	_, err = ECIESDecrypt(other, encrypted)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = ECIESDecrypt(p256, encrypted)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = ECIESDecrypt(key, encrypted[:eciesHeaderSize+10])
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	_, err = ECIESDecrypt(key, encrypted[:eciesHeaderSize+32+8])
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = ECIESDecrypt(key, flipByte(encrypted, 0))
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	_, err = ECIESDecrypt(key, nil)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	_, err = ECIESEncrypt(key.PublicKey(), []byte("data"), DES)
+	assert.True(t, errors.Is(err, ErrInvalidAlgorithm))
+	p384, _ := GenerateECDHKey(ecdh.P384())
+	_, err = ECIESEncrypt(p384.PublicKey(), []byte("data"), AES)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
@@ -0,0 +1,58 @@
+package crypt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+)
+
+// generate ed25519 private key
+func GenerateEd25519Key() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	return key, err
+}
+
+// 读取PKCS#8格式的ed25519私钥, 不是ed25519私钥时返回*KeyTypeError
+func ParseEd25519PrivateKey(in []byte) (ed25519.PrivateKey, error) {
+	pri, err := ParsePKCS8PrivateKey(in)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pri.(ed25519.PrivateKey)
+	if !ok {
+		return nil, keyTypeError("ed25519.PrivateKey", pri)
+	}
+	return key, nil
+}
+
+// 读取ed25519公钥(PKIX), 不是ed25519公钥时返回*KeyTypeError
+func ParseEd25519PublicKey(in []byte) (ed25519.PublicKey, error) {
+	pub, err := ParsePKIXPublicKey(in)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, keyTypeError("ed25519.PublicKey", pub)
+	}
+	return key, nil
+}
+
+// sign data with ed25519 private key, data is signed directly without pre-hash
+func SignEd25519(key ed25519.PrivateKey, data []byte) ([]byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: ed25519 private key length %d", ErrInvalidKey, len(key))
+	}
+	return ed25519.Sign(key, data), nil
+}
+
+// verify ed25519 signature, returns ErrVerification if signature is invalid
+func VerifyEd25519(key ed25519.PublicKey, data, sig []byte) error {
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: ed25519 public key length %d", ErrInvalidKey, len(key))
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return ErrVerification
+	}
+	return nil
+}
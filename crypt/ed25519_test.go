@@ -0,0 +1,45 @@
+package crypt
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEd25519Vector(t *testing.T) {
+	// rfc 8032 test 1
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	key := ed25519.NewKeyFromSeed(seed)
+	expect := "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b"
+
+	sig, err := SignEd25519(key, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, hex.EncodeToString(sig))
+	assert.Nil(t, VerifyEd25519(key.Public().(ed25519.PublicKey), nil, sig))
+	assert.True(t, errors.Is(VerifyEd25519(key.Public().(ed25519.PublicKey), []byte("x"), sig), ErrVerification))
+
+	_, err = SignEd25519(key[:32], nil)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestParseEd25519Key(t *testing.T) {
+	// openssl genpkey -algorithm ed25519
+	key, err := ParseEd25519PrivateKey([]byte("MC4CAQAwBQYDK2VwBCIEIISs9mZN+O+BpB6ObWw1jwP+1E3qbpXB/W6AqEezt81J"))
+	assert.Nil(t, err)
+	pub, err := ParseEd25519PublicKey([]byte(`-----BEGIN PUBLIC KEY-----
+MCowBQYDK2VwAyEACF4xJhs9c5DZ0Eg0HX2d9X26IojsRnB8tGDz0QG4vQ8=
+-----END PUBLIC KEY-----`))
+	assert.Nil(t, err)
+	assert.True(t, pub.Equal(key.Public()))
+
+	sig, err := SignEd25519(key, []byte("abc"))
+	assert.Nil(t, err)
+	assert.Nil(t, VerifyEd25519(pub, []byte("abc"), sig))
+
+	var typeErr *KeyTypeError
+	_, err = ParseEd25519PrivateKey([]byte(testECPKCS8Key))
+	assert.True(t, errors.As(err, &typeErr))
+}
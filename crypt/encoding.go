@@ -0,0 +1,52 @@
+package crypt
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// output encoding of digests, macs and signatures
+type Encoding string
+
+const (
+	//lower case hex
+	HEX Encoding = "HEX"
+	//upper case hex
+	HEXUPPER Encoding = "HEXUPPER"
+	//standard base64
+	BASE64 Encoding = "BASE64"
+	//url safe base64 without padding
+	BASE64URL Encoding = "BASE64URL"
+)
+
+// EncodeToString 将数据编码为字符串, 未知的编码方式返回ErrInvalidEncoding
+func (e Encoding) EncodeToString(src []byte) (string, error) {
+	switch e {
+	case HEX:
+		return hex.EncodeToString(src), nil
+	case HEXUPPER:
+		return strings.ToUpper(hex.EncodeToString(src)), nil
+	case BASE64:
+		return base64.StdEncoding.EncodeToString(src), nil
+	case BASE64URL:
+		return base64.RawURLEncoding.EncodeToString(src), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidEncoding, e)
+	}
+}
+
+// DecodeString 解码字符串, hex不区分大小写, 未知的编码方式返回ErrInvalidEncoding
+func (e Encoding) DecodeString(s string) ([]byte, error) {
+	switch e {
+	case HEX, HEXUPPER:
+		return hex.DecodeString(s)
+	case BASE64:
+		return base64.StdEncoding.DecodeString(s)
+	case BASE64URL:
+		return base64.RawURLEncoding.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEncoding, e)
+	}
+}
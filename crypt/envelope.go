@@ -0,0 +1,148 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// 信封格式版本
+const envelopeVersion = 1
+
+// 信封中包装数据密钥的算法
+const (
+	envelopeRSAOAEP = 1 // RSA-OAEP-SHA256
+	envelopeSM2     = 2 // SM2, C1C3C2
+)
+
+// 信封中加密数据的算法
+const (
+	envelopeAESGCM = 1 // AES-256-GCM
+	envelopeSM4GCM = 2 // SM4-GCM
+)
+
+// envelope头部长度: 版本(1) | 包装算法(1) | 数据算法(1) | 包装密钥长度(2)
+const envelopeHeaderSize = 5
+
+// SealEnvelope 数字信封加密, 适用于大数据量的非对称加密
+// 随机生成数据密钥, 使用AES-256-GCM或SM4-GCM加密数据, 再使用接收方的公钥包装数据密钥
+// 参数:
+//   - pub: 接收方公钥, *rsa.PublicKey(RSA-OAEP-SHA256)或*sm2.PublicKey(SM2 C1C3C2)
+//   - data: 需要加密的原始数据
+//   - alg: 数据加密算法, AES或SM4
+//
+// 返回:
+//   - 信封: 版本(1) | 包装算法(1) | 数据算法(1) | 包装密钥长度(2, 大端) | 包装密钥 | nonce(12) | 密文 | tag(16)
+//     头部和包装密钥作为GCM的附加数据, 任何修改都会导致解密失败
+//   - 错误信息(如果有)
+func SealEnvelope(pub crypto.PublicKey, data []byte, alg Algorithm) ([]byte, error) {
+	var dataAlg byte
+	var dataKey []byte
+	switch alg {
+	case AES:
+		dataAlg, dataKey = envelopeAESGCM, make([]byte, 32)
+	case SM4:
+		dataAlg, dataKey = envelopeSM4GCM, make([]byte, 16)
+	default:
+		return nil, fmt.Errorf("%w: envelope data algorithm %s", ErrInvalidAlgorithm, alg)
+	}
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	var wrapAlg byte
+	var wrapped []byte
+	var err error
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		wrapAlg = envelopeRSAOAEP
+		wrapped, err = PublicEncryptOAEP(key, dataKey, SHA256, nil)
+	case *sm2.PublicKey:
+		wrapAlg = envelopeSM2
+		wrapped, err = SM2Encrypt(key, dataKey, C1C3C2, false)
+	default:
+		return nil, keyTypeError("*rsa.PublicKey or *sm2.PublicKey", pub)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) > 0xffff {
+		return nil, fmt.Errorf("%w: wrapped key too long", ErrInvalidKey)
+	}
+
+	header := make([]byte, envelopeHeaderSize, envelopeHeaderSize+len(wrapped))
+	header[0], header[1], header[2] = envelopeVersion, wrapAlg, dataAlg
+	binary.BigEndian.PutUint16(header[3:], uint16(len(wrapped)))
+	header = append(header, wrapped...)
+
+	c, err := NewCipher(alg, dataKey, WithMode(GCM), WithAAD(header))
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := c.Encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, sealed...), nil
+}
+
+// OpenEnvelope 使用私钥解开SealEnvelope生成的信封
+// priv为*rsa.PrivateKey或*sm2.PrivateKey, 需要与信封的包装算法一致
+// 格式错误时返回ErrInvalidFormat, 私钥不匹配或数据被篡改时返回ErrAuthentication
+func OpenEnvelope(priv crypto.PrivateKey, envelope []byte) ([]byte, error) {
+	if len(envelope) < envelopeHeaderSize {
+		return nil, fmt.Errorf("%w: envelope too short", ErrInvalidFormat)
+	}
+	if envelope[0] != envelopeVersion {
+		return nil, fmt.Errorf("%w: unsupported envelope version %d", ErrInvalidFormat, envelope[0])
+	}
+	headerSize := envelopeHeaderSize + int(binary.BigEndian.Uint16(envelope[3:]))
+	if len(envelope) < headerSize {
+		return nil, fmt.Errorf("%w: envelope too short", ErrInvalidFormat)
+	}
+	header, wrapped := envelope[:headerSize], envelope[envelopeHeaderSize:headerSize]
+
+	var alg Algorithm
+	switch envelope[2] {
+	case envelopeAESGCM:
+		alg = AES
+	case envelopeSM4GCM:
+		alg = SM4
+	default:
+		return nil, fmt.Errorf("%w: unsupported envelope data algorithm %d", ErrInvalidFormat, envelope[2])
+	}
+
+	var dataKey []byte
+	var err error
+	switch envelope[1] {
+	case envelopeRSAOAEP:
+		key, ok := priv.(*rsa.PrivateKey)
+		if !ok {
+			return nil, keyTypeError("*rsa.PrivateKey", priv)
+		}
+		dataKey, err = PrivateDecryptOAEP(key, wrapped, SHA256, nil)
+	case envelopeSM2:
+		key, ok := priv.(*sm2.PrivateKey)
+		if !ok {
+			return nil, keyTypeError("*sm2.PrivateKey", priv)
+		}
+		dataKey, err = SM2Decrypt(key, wrapped, C1C3C2, false)
+	default:
+		return nil, fmt.Errorf("%w: unsupported envelope key algorithm %d", ErrInvalidFormat, envelope[1])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unwrap data key: %s", ErrAuthentication, err.Error())
+	}
+
+	c, err := NewCipher(alg, dataKey, WithMode(GCM), WithAAD(header))
+	if err != nil {
+		// 数据密钥长度与算法不符
+		return nil, fmt.Errorf("%w: %s", ErrAuthentication, err.Error())
+	}
+	return c.Decrypt(envelope[headerSize:])
+}
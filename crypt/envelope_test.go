@@ -0,0 +1,77 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	sm2Key, _ := GenerateSM2Key()
+	plain := bytes.Repeat([]byte("large payload "), 10000)
+
+	for _, c := range []struct {
+		pub  any
+		priv any
+		alg  Algorithm
+	}{
+		{&testRSAKey.PublicKey, testRSAKey, AES},
+		{&testRSAKey.PublicKey, testRSAKey, SM4},
+		{&sm2Key.PublicKey, sm2Key, SM4},
+		{&sm2Key.PublicKey, sm2Key, AES},
+	} {
+		envelope, err := SealEnvelope(c.pub, plain, c.alg)
+		if !assert.Nil(t, err, "%T %s", c.pub, c.alg) {
+			continue
+		}
+		assert.Equal(t, byte(1), envelope[0])
+		assert.True(t, len(envelope) < len(plain)+512, "%T %s", c.pub, c.alg)
+
+		opened, err := OpenEnvelope(c.priv, envelope)
+		assert.Nil(t, err, "%T %s", c.pub, c.alg)
+		assert.Equal(t, plain, opened, "%T %s", c.pub, c.alg)
+
+		// tampered header, wrapped key and ciphertext
+		for _, i := range []int{2, 10, len(envelope) - 1} {
+			tampered := append([]byte(nil), envelope...)
+			tampered[i] ^= 1
+			_, err = OpenEnvelope(c.priv, tampered)
+			assert.NotNil(t, err, "%T %s %d", c.pub, c.alg, i)
+		}
+	}
+
+	envelope, err := SealEnvelope(&testRSAKey.PublicKey, nil, AES)
+	assert.Nil(t, err)
+	opened, err := OpenEnvelope(testRSAKey, envelope)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(opened))
+}
+
+func TestEnvelopeErrors(t *testing.T) {
+	sm2Key, _ := GenerateSM2Key()
+	otherRSAKey, _ := GenerateRSAKey(2048)
+
+	_, err := SealEnvelope(&testRSAKey.PublicKey, []byte("x"), DES)
+	assert.True(t, errors.Is(err, ErrInvalidAlgorithm))
+	var typeErr *KeyTypeError
+	_, err = SealEnvelope(testRSAKey, []byte("x"), AES)
+	assert.True(t, errors.As(err, &typeErr))
+
+	envelope, _ := SealEnvelope(&testRSAKey.PublicKey, []byte("x"), AES)
+	_, err = OpenEnvelope(otherRSAKey, envelope)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = OpenEnvelope(sm2Key, envelope)
+	assert.True(t, errors.As(err, &typeErr))
+
+	envelope, _ = SealEnvelope(&sm2Key.PublicKey, []byte("x"), SM4)
+	other, _ := GenerateSM2Key()
+	_, err = OpenEnvelope(other, envelope)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	for _, bad := range [][]byte{nil, {1, 1, 1}, {2, 1, 1, 0, 0}, {1, 1, 1, 0xff, 0xff, 1}, {1, 9, 1, 0, 0}, {1, 1, 9, 0, 0}} {
+		_, err = OpenEnvelope(testRSAKey, bad)
+		assert.True(t, errors.Is(err, ErrInvalidFormat), "%x", bad)
+	}
+}
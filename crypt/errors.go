@@ -0,0 +1,58 @@
+package crypt
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidKey 密钥长度不符合算法要求
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrInvalidIV iv(nonce)长度不符合模式要求
+	ErrInvalidIV = errors.New("invalid iv")
+	// ErrInvalidMode 不支持的加密模式
+	ErrInvalidMode = errors.New("invalid mode")
+	// ErrInvalidPadding 不支持的填充方式, 或解密后的填充校验失败
+	ErrInvalidPadding = errors.New("invalid padding")
+	// ErrInvalidAlgorithm 不支持的算法
+	ErrInvalidAlgorithm = errors.New("invalid algorithm")
+	// ErrNotFullBlocks 分组模式下数据长度不是分组长度的整数倍
+	ErrNotFullBlocks = errors.New("input not full blocks")
+	// ErrInvalidHash 不支持的hash算法
+	ErrInvalidHash = errors.New("invalid hash")
+	// ErrInvalidKeyFormat 不支持的密钥格式
+	ErrInvalidKeyFormat = errors.New("invalid key format")
+	// ErrVerification 签名校验失败
+	ErrVerification = errors.New("verification failed")
+	// ErrAuthentication GCM认证失败, 密文、附加数据(AAD)被篡改或密钥不匹配
+	ErrAuthentication = errors.New("message authentication failed")
+	// ErrInvalidPassword 加密私钥的密码错误
+	ErrInvalidPassword = errors.New("invalid password")
+	// ErrInvalidFormat 数据格式错误, 如信封的版本号或长度不正确
+	ErrInvalidFormat = errors.New("invalid data format")
+	// ErrKeyNotFound 密钥环中没有对应ID的密钥
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrTokenExpired JWT已过期(exp)
+	ErrTokenExpired = errors.New("token expired")
+	// ErrInvalidClaims JWT的nbf, iat, iss或aud校验失败
+	ErrInvalidClaims = errors.New("invalid claims")
+	// ErrInvalidEncoding 不支持的输出编码方式
+	ErrInvalidEncoding = errors.New("invalid encoding")
+)
+
+// KeyTypeError 解析得到的密钥类型与期望不符
+type KeyTypeError struct {
+	// 期望的密钥类型
+	Want string
+	// 实际的密钥类型
+	Got string
+}
+
+func (e *KeyTypeError) Error() string {
+	return fmt.Sprintf("unexpected key type %s, want %s", e.Got, e.Want)
+}
+
+// keyTypeError 创建KeyTypeError
+func keyTypeError(want string, got any) error {
+	return &KeyTypeError{Want: want, Got: fmt.Sprintf("%T", got)}
+}
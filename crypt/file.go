@@ -0,0 +1,142 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// 加密文件格式
+// 魔数"ZKEF"(4) | 版本(1) | 算法(1) | nonce前缀(7) | 分段密文
+// 分段密文与NewEncryptWriter的GCM模式一致: 每64KB明文为一段, 每段带16字节tag,
+// nonce = 前缀 | 段序号 | 结束标记, 头部作为每段的附加数据, 可以检测截断, 重排和头部篡改
+var fileMagic = []byte("ZKEF")
+
+const (
+	fileVersion    = 1
+	fileHeaderSize = 4 + 1 + 1 + streamNoncePrefixSize
+)
+
+// 文件中的算法
+const (
+	fileAESGCM = 1 // AES-GCM
+	fileSM4GCM = 2 // SM4-GCM
+)
+
+// EncryptFile 使用AES-GCM加密文件, key长度为16/24/32, 加密过程中内存占用固定
+// 先写入dst所在目录的临时文件, 完成后再重命名为dst, 使用SM4时见EncryptStream
+func EncryptFile(src, dst string, key []byte) error {
+	return transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		return EncryptStream(w, r, AES, key)
+	})
+}
+
+// DecryptFile 解密EncryptFile或EncryptStream生成的文件, 算法从文件头读取
+// 所有分段校验通过后才生成dst, 数据被截断或篡改时返回ErrAuthentication且不生成dst
+func DecryptFile(src, dst string, key []byte) error {
+	return transformFile(src, dst, func(w io.Writer, r io.Reader) error {
+		return DecryptStream(w, r, key)
+	})
+}
+
+// EncryptStream 读取src直到EOF, 加密后写入dst, 格式与EncryptFile相同
+// alg为AES或SM4
+func EncryptStream(dst io.Writer, src io.Reader, alg Algorithm, key []byte) error {
+	header := make([]byte, fileHeaderSize)
+	copy(header, fileMagic)
+	header[4] = fileVersion
+	switch alg {
+	case AES:
+		header[5] = fileAESGCM
+	case SM4:
+		header[5] = fileSM4GCM
+	default:
+		return fmt.Errorf("%w: file algorithm %s", ErrInvalidAlgorithm, alg)
+	}
+	if _, err := io.ReadFull(rand.Reader, header[6:]); err != nil {
+		return err
+	}
+
+	c, err := newFileCipher(alg, key, header)
+	if err != nil {
+		return err
+	}
+	if _, err = dst.Write(header); err != nil {
+		return err
+	}
+	w, err := newGCMWriter(dst, c, header[6:])
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, src); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// DecryptStream 读取EncryptStream的输出, 解密后写入dst
+// 每段数据校验通过后才写入dst, 数据被截断时已写入的数据不完整, 需要丢弃
+func DecryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	header := make([]byte, fileHeaderSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		return fmt.Errorf("%w: file header too short", ErrInvalidFormat)
+	}
+	if !bytes.Equal(header[:4], fileMagic) {
+		return fmt.Errorf("%w: not an encrypted file", ErrInvalidFormat)
+	}
+	if header[4] != fileVersion {
+		return fmt.Errorf("%w: unsupported file version %d", ErrInvalidFormat, header[4])
+	}
+	var alg Algorithm
+	switch header[5] {
+	case fileAESGCM:
+		alg = AES
+	case fileSM4GCM:
+		alg = SM4
+	default:
+		return fmt.Errorf("%w: unsupported file algorithm %d", ErrInvalidFormat, header[5])
+	}
+
+	c, err := newFileCipher(alg, key, header)
+	if err != nil {
+		return err
+	}
+	r, err := newGCMReader(src, c, header[6:])
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+// newFileCipher 创建GCM加密器, 文件头作为附加数据, nonce前缀保存在文件头中
+func newFileCipher(alg Algorithm, key, header []byte) (*Cipher, error) {
+	return NewCipher(alg, key, WithMode(GCM), WithAAD(header))
+}
+
+// transformFile 读取src处理后写入dst, 先写入临时文件, 成功后再重命名
+func transformFile(src, dst string, fn func(w io.Writer, r io.Reader) error) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+
+	if err = fn(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), dst)
+}
@@ -0,0 +1,105 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptFile(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{7}, 32)
+
+	for _, size := range []int{0, 100, streamSegmentSize, 3*streamSegmentSize + 17} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+		src := filepath.Join(dir, "plain.bin")
+		assert.Nil(t, os.WriteFile(src, plain, 0644))
+
+		encrypted := filepath.Join(dir, "plain.bin.enc")
+		assert.Nil(t, EncryptFile(src, encrypted, key), "%d", size)
+		data, _ := os.ReadFile(encrypted)
+		assert.Equal(t, []byte("ZKEF\x01\x01"), data[:6])
+
+		decrypted := filepath.Join(dir, "plain.dec")
+		assert.Nil(t, DecryptFile(encrypted, decrypted, key), "%d", size)
+		out, _ := os.ReadFile(decrypted)
+		assert.True(t, bytes.Equal(plain, out), "%d", size)
+	}
+
+	// no temp files left behind
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 3, len(entries))
+
+	assert.NotNil(t, EncryptFile(filepath.Join(dir, "missing"), filepath.Join(dir, "x.enc"), key))
+	assert.NotNil(t, EncryptFile(filepath.Join(dir, "plain.bin"), filepath.Join(dir, "x.enc"), key[:7]))
+	_, err := os.Stat(filepath.Join(dir, "x.enc"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestEncryptStream(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 16)
+	plain := make([]byte, 2*streamSegmentSize+100)
+	rand.Read(plain)
+
+	var buf bytes.Buffer
+	assert.Nil(t, EncryptStream(&buf, bytes.NewReader(plain), SM4, key))
+	encrypted := buf.Bytes()
+	segment := streamSegmentSize + 16
+
+	var out bytes.Buffer
+	assert.Nil(t, DecryptStream(&out, bytes.NewReader(encrypted), key))
+	assert.True(t, bytes.Equal(plain, out.Bytes()))
+
+	swapped := append([]byte(nil), encrypted[:fileHeaderSize]...)
+	swapped = append(swapped, encrypted[fileHeaderSize+segment:fileHeaderSize+2*segment]...)
+	swapped = append(swapped, encrypted[fileHeaderSize:fileHeaderSize+segment]...)
+	swapped = append(swapped, encrypted[fileHeaderSize+2*segment:]...)
+
+	for name, broken := range map[string][]byte{
+		"truncated":  encrypted[:fileHeaderSize+2*segment],
+		"reordered":  swapped,
+		"last byte":  flipByte(encrypted, len(encrypted)-1),
+		"nonce":      flipByte(encrypted, 8),
+		"algorithm":  flipByte(encrypted, 5),
+		"magic":      flipByte(encrypted, 0),
+		"version":    flipByte(encrypted, 4),
+		"header":     encrypted[:5],
+		"no segment": encrypted[:fileHeaderSize],
+	} {
+		err := DecryptStream(&bytes.Buffer{}, bytes.NewReader(broken), key)
+		assert.True(t, errors.Is(err, ErrAuthentication) || errors.Is(err, ErrInvalidFormat), "%s: %v", name, err)
+	}
+
+	err := DecryptStream(&bytes.Buffer{}, bytes.NewReader(encrypted), bytes.Repeat([]byte{8}, 16))
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	assert.True(t, errors.Is(EncryptStream(&buf, bytes.NewReader(plain), DES, key[:8]), ErrInvalidAlgorithm))
+}
+
+func TestDecryptFileBroken(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{7}, 32)
+	src := filepath.Join(dir, "plain.bin")
+	assert.Nil(t, os.WriteFile(src, make([]byte, 2*streamSegmentSize), 0644))
+	encrypted := filepath.Join(dir, "plain.bin.enc")
+	assert.Nil(t, EncryptFile(src, encrypted, key))
+
+	// partial plaintext of a truncated file never reaches dst
+	data, _ := os.ReadFile(encrypted)
+	assert.Nil(t, os.WriteFile(encrypted, data[:len(data)-20], 0644))
+	dst := filepath.Join(dir, "plain.dec")
+	assert.True(t, errors.Is(DecryptFile(encrypted, dst, key), ErrAuthentication))
+	_, err := os.Stat(dst)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func flipByte(data []byte, i int) []byte {
+	out := append([]byte(nil), data...)
+	out[i] ^= 1
+	return out
+}
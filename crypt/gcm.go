@@ -0,0 +1,109 @@
+package crypt
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// GCMNonceSize GCM推荐的nonce长度
+const GCMNonceSize = 12
+
+// newGCM 创建GCM模式的AEAD, nonce长度为0时使用推荐长度, 不能短于推荐长度
+func newGCM(block cipher.Block, nonceSize int) (cipher.AEAD, error) {
+	if block.BlockSize() != 16 {
+		return nil, fmt.Errorf("%w: GCM requires 16 bytes block size", ErrInvalidMode)
+	}
+	if nonceSize > 0 && nonceSize < GCMNonceSize {
+		return nil, fmt.Errorf("%w: nonce length must be at least %d in mode GCM", ErrInvalidIV, GCMNonceSize)
+	}
+	if nonceSize == 0 || nonceSize == GCMNonceSize {
+		return cipher.NewGCM(block)
+	}
+	return cipher.NewGCMWithNonceSize(block, nonceSize)
+}
+
+// gcmSeal 使用GCM模式加密
+// nonce为空时随机生成, 并作为前缀写入密文: nonce|ciphertext|tag
+// nonce不为空时只返回 ciphertext|tag
+func gcmSeal(aead cipher.AEAD, nonce, plainText, aad []byte) ([]byte, error) {
+	if len(nonce) > 0 {
+		return aead.Seal(nil, nonce, plainText, aad), nil
+	}
+
+	out := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plainText)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, out); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, out, plainText, aad), nil
+}
+
+// gcmOpen 使用GCM模式解密并校验tag
+// nonce为空时从密文前缀读取nonce, 与gcmSeal对应
+func gcmOpen(aead cipher.AEAD, nonce, cipherText, aad []byte) ([]byte, error) {
+	if len(nonce) == 0 {
+		if len(cipherText) < aead.NonceSize() {
+			return nil, ErrAuthentication
+		}
+		nonce, cipherText = cipherText[:aead.NonceSize()], cipherText[aead.NonceSize():]
+	}
+	if len(cipherText) < aead.Overhead() {
+		return nil, ErrAuthentication
+	}
+	plainText, err := aead.Open(nil, nonce, cipherText, aad)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return plainText, nil
+}
+
+// gcmCrypt 使用GCM模式加密或解密
+func gcmCrypt(alg Algorithm, data, key, nonce, aad []byte, encrypt bool) ([]byte, error) {
+	c, err := newCipher(alg, key, WithMode(GCM), WithPadding(NONE), WithIV(nonce), WithAAD(aad))
+	if err != nil {
+		return nil, err
+	}
+	if encrypt {
+		return c.Encrypt(data)
+	}
+	return c.Decrypt(data)
+}
+
+// AESGCMEncrypt 使用AES-GCM加密数据
+// 参数:
+//   - data: 需要加密的原始数据
+//   - key: 加密密钥(16/24/32字节)
+//   - nonce: 随机数, 不能短于12字节, 为空时自动生成并作为密文前缀
+//   - aad: 附加认证数据, 不加密但参与认证, 可为空
+//
+// 返回:
+//   - 加密后的数据(包含16字节tag)
+//   - 错误信息(如果有)
+func AESGCMEncrypt(data, key, nonce, aad []byte) ([]byte, error) {
+	return gcmCrypt(AES, data, key, nonce, aad, true)
+}
+
+// AESGCMDecrypt 使用AES-GCM解密数据
+// 参数:
+//   - src: 需要解密的加密数据
+//   - key: 解密密钥
+//   - nonce: 加密时使用的随机数, 为空时从密文前缀读取
+//   - aad: 加密时使用的附加认证数据
+//
+// 返回:
+//   - 解密后的原始数据
+//   - 错误信息(认证失败时为ErrAuthentication)
+func AESGCMDecrypt(src, key, nonce, aad []byte) ([]byte, error) {
+	return gcmCrypt(AES, src, key, nonce, aad, false)
+}
+
+// SM4GCMEncrypt 使用SM4-GCM加密数据, 参数同AESGCMEncrypt
+func SM4GCMEncrypt(data, key, nonce, aad []byte) ([]byte, error) {
+	return gcmCrypt(SM4, data, key, nonce, aad, true)
+}
+
+// SM4GCMDecrypt 使用SM4-GCM解密数据, 参数同AESGCMDecrypt
+func SM4GCMDecrypt(src, key, nonce, aad []byte) ([]byte, error) {
+	return gcmCrypt(SM4, src, key, nonce, aad, false)
+}
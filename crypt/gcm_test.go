@@ -0,0 +1,93 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAESGCMVector(t *testing.T) {
+	// gcm spec test case 3
+	key, _ := hex.DecodeString("feffe9928665731c6d6a8f9467308308")
+	nonce, _ := hex.DecodeString("cafebabefacedbaddecaf888")
+	plain, _ := hex.DecodeString("d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b391aafd255")
+	expect := "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091473f5985" +
+		"4d5c2af327cd64a62cf35abd2ba6fab4"
+
+	encrypted, err := AESEncrypt(plain, key, nonce, GCM, NONE)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, hex.EncodeToString(encrypted))
+
+	decrypted, err := AESDecrypt(encrypted, key, nonce, GCM, NONE)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+}
+
+func TestGCMRandomNonce(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	aad := []byte("order-10086")
+	plain := []byte("hello gcm")
+
+	for _, alg := range []struct {
+		name    string
+		encrypt func(data, key, nonce, aad []byte) ([]byte, error)
+		decrypt func(src, key, nonce, aad []byte) ([]byte, error)
+	}{
+		{"AES", AESGCMEncrypt, AESGCMDecrypt},
+		{"SM4", SM4GCMEncrypt, SM4GCMDecrypt},
+	} {
+		encrypted, err := alg.encrypt(plain, key, nil, aad)
+		assert.Nil(t, err, alg.name)
+		assert.Equal(t, GCMNonceSize+len(plain)+16, len(encrypted), alg.name)
+
+		decrypted, err := alg.decrypt(encrypted, key, nil, aad)
+		assert.Nil(t, err, alg.name)
+		assert.Equal(t, plain, decrypted, alg.name)
+
+		_, err = alg.decrypt(encrypted, key, nil, []byte("order-10087"))
+		assert.True(t, errors.Is(err, ErrAuthentication), alg.name)
+
+		encrypted[len(encrypted)-1] ^= 1
+		_, err = alg.decrypt(encrypted, key, nil, aad)
+		assert.True(t, errors.Is(err, ErrAuthentication), alg.name)
+
+		_, err = alg.decrypt(encrypted[:5], key, nil, aad)
+		assert.True(t, errors.Is(err, ErrAuthentication), alg.name)
+	}
+}
+
+func TestSM4GCMMode(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	plain := []byte("hello sm4 gcm")
+
+	encrypted, err := SM4Encrypt(plain, key, nil, GCM, NONE)
+	assert.Nil(t, err)
+	decrypted, err := SM4Decrypt(encrypted, key, nil, GCM, NONE)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	_, err = SM4Encrypt(plain, key, nil, GCM, PKCS5)
+	assert.NotNil(t, err)
+}
+
+func TestGCMNonceSize(t *testing.T) {
+	key := []byte("1234567890abcdef")
+	plain := []byte("short nonce")
+
+	for _, size := range []int{1, 8, GCMNonceSize - 1} {
+		_, err := AESEncrypt(plain, key, make([]byte, size), GCM, NONE)
+		assert.True(t, errors.Is(err, ErrInvalidIV), "%d", size)
+		_, err = SM4GCMDecrypt(plain, key, make([]byte, size), nil)
+		assert.True(t, errors.Is(err, ErrInvalidIV), "%d", size)
+	}
+
+	// 长于推荐长度的nonce仍然可用
+	nonce := make([]byte, 16)
+	encrypted, err := AESGCMEncrypt(plain, key, nonce, nil)
+	assert.Nil(t, err)
+	decrypted, err := AESGCMDecrypt(encrypted, key, nonce, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+}
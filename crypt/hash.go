@@ -0,0 +1,170 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// hash algorithm
+type Hash string
+
+const (
+	//md5
+	MD5 Hash = "MD5"
+	//sha1
+	SHA1 Hash = "SHA1"
+	//sha256
+	SHA256 Hash = "SHA256"
+	//sha384
+	SHA384 Hash = "SHA384"
+	//sha512
+	SHA512 Hash = "SHA512"
+	//sm3
+	SM3 Hash = "SM3"
+	//crc32 IEEE, checksum only, can not be used in signatures
+	CRC32 Hash = "CRC32"
+)
+
+// newHash 返回hash算法的构造函数
+func (h Hash) newHash() (func() hash.Hash, error) {
+	switch h {
+	case MD5:
+		return md5.New, nil
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA384:
+		return sha512.New384, nil
+	case SHA512:
+		return sha512.New, nil
+	case SM3:
+		return sm3.New, nil
+	case CRC32:
+		return func() hash.Hash { return crc32.NewIEEE() }, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidHash, h)
+	}
+}
+
+// cryptoHash 返回对应的crypto.Hash, SM3未在crypto包中注册, 返回0
+func (h Hash) cryptoHash() crypto.Hash {
+	switch h {
+	case MD5:
+		return crypto.MD5
+	case SHA1:
+		return crypto.SHA1
+	case SHA256:
+		return crypto.SHA256
+	case SHA384:
+		return crypto.SHA384
+	case SHA512:
+		return crypto.SHA512
+	default:
+		return 0
+	}
+}
+
+// macHash 返回用于HMAC, 密钥派生和OAEP的hash算法, CRC32不是密码学hash, 不能使用
+func (h Hash) macHash() (func() hash.Hash, error) {
+	if h == CRC32 {
+		return nil, fmt.Errorf("%w: %s is not a cryptographic hash", ErrInvalidHash, h)
+	}
+	return h.newHash()
+}
+
+// signHash 返回用于签名的hash算法, 只允许SHA256, SHA384, SHA512和SM3, MD5和SHA1已不安全
+func (h Hash) signHash() (func() hash.Hash, error) {
+	switch h {
+	case SHA256, SHA384, SHA512, SM3:
+		return h.newHash()
+	default:
+		return nil, fmt.Errorf("%w: %s can not be used in signatures", ErrInvalidHash, h)
+	}
+}
+
+// digest 计算数据的摘要
+func (h Hash) digest(data []byte) ([]byte, error) {
+	newHash, err := h.newHash()
+	if err != nil {
+		return nil, err
+	}
+	d := newHash()
+	d.Write(data)
+	return d.Sum(nil), nil
+}
+
+// NewHash 创建流式计算摘要的hash.Hash
+func NewHash(h Hash) (hash.Hash, error) {
+	newHash, err := h.newHash()
+	if err != nil {
+		return nil, err
+	}
+	return newHash(), nil
+}
+
+// Digest 计算数据的摘要
+func Digest(h Hash, data []byte) ([]byte, error) {
+	return h.digest(data)
+}
+
+// DigestEncode 计算数据的摘要, 并按指定方式编码
+func DigestEncode(h Hash, data []byte, enc Encoding) (string, error) {
+	sum, err := h.digest(data)
+	if err != nil {
+		return "", err
+	}
+	return enc.EncodeToString(sum)
+}
+
+// DigestReader 读取r直到EOF, 一次读取同时计算多个摘要
+//
+//	sums, err := DigestReader(r, MD5, SHA256, SM3)
+//	fmt.Println(hex.EncodeToString(sums[SHA256]))
+func DigestReader(r io.Reader, hashes ...Hash) (map[Hash][]byte, error) {
+	if len(hashes) == 0 {
+		return nil, fmt.Errorf("%w: no hash specified", ErrInvalidHash)
+	}
+	hs := make(map[Hash]hash.Hash, len(hashes))
+	writers := make([]io.Writer, 0, len(hashes))
+	for _, h := range hashes {
+		if _, ok := hs[h]; ok {
+			continue
+		}
+		d, err := NewHash(h)
+		if err != nil {
+			return nil, err
+		}
+		hs[h] = d
+		writers = append(writers, d)
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, err
+	}
+	sums := make(map[Hash][]byte, len(hs))
+	for h, d := range hs {
+		sums[h] = d.Sum(nil)
+	}
+	return sums, nil
+}
+
+// DigestFile 读取文件一次, 同时计算多个摘要, 文件不存在或读取失败时返回错误
+func DigestFile(path string, hashes ...Hash) (map[Hash][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DigestReader(f, hashes...)
+}
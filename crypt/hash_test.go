@@ -0,0 +1,79 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var hashVectors = map[Hash]string{
+	MD5:    "900150983cd24fb0d6963f7d28e17f72",
+	SHA1:   "a9993e364706816aba3e25717850c26c9cd0d89d",
+	SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	SHA512: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+	SM3:    "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0",
+	CRC32:  "352441c2",
+}
+
+func TestDigest(t *testing.T) {
+	for h, expect := range hashVectors {
+		sum, err := Digest(h, []byte("abc"))
+		assert.Nil(t, err, "%s", h)
+		assert.Equal(t, expect, hex.EncodeToString(sum), "%s", h)
+
+		out, err := DigestEncode(h, []byte("abc"), HEX)
+		assert.Nil(t, err, "%s", h)
+		assert.Equal(t, expect, out, "%s", h)
+	}
+
+	_, err := Digest("SHA3", []byte("abc"))
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+	_, err = SignPKCS1v15(testRSAKey, []byte("abc"), CRC32)
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+
+	// weak hashes are not allowed in signatures
+	ecKey, _ := ParseECPrivateKey([]byte(testECKey))
+	for _, h := range []Hash{MD5, SHA1} {
+		_, err = SignPKCS1v15(testRSAKey, []byte("abc"), h)
+		assert.True(t, errors.Is(err, ErrInvalidHash), "%s", h)
+		_, err = SignPSS(testRSAKey, []byte("abc"), h)
+		assert.True(t, errors.Is(err, ErrInvalidHash), "%s", h)
+		_, err = SignECDSA(ecKey, []byte("abc"), h, ASN1)
+		assert.True(t, errors.Is(err, ErrInvalidHash), "%s", h)
+	}
+}
+
+func TestDigestReader(t *testing.T) {
+	hashes := []Hash{MD5, SHA1, SHA256, SHA512, SM3, CRC32, SHA256}
+	sums, err := DigestReader(strings.NewReader("abc"), hashes...)
+	assert.Nil(t, err)
+	assert.Equal(t, len(hashVectors), len(sums))
+	for h, expect := range hashVectors {
+		assert.Equal(t, expect, hex.EncodeToString(sums[h]), "%s", h)
+	}
+
+	_, err = DigestReader(strings.NewReader("abc"))
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+	_, err = DigestReader(strings.NewReader("abc"), MD5, "SHA3")
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+}
+
+func TestDigestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc.txt")
+	assert.Nil(t, os.WriteFile(path, []byte("abc"), 0644))
+
+	sums, err := DigestFile(path, MD5, SM3)
+	assert.Nil(t, err)
+	assert.Equal(t, hashVectors[MD5], hex.EncodeToString(sums[MD5]))
+	assert.Equal(t, hashVectors[SM3], hex.EncodeToString(sums[SM3]))
+	assert.Equal(t, hashVectors[MD5], Md5File(path))
+
+	_, err = DigestFile(path+".missing", MD5)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.Equal(t, "", Md5File(path+".missing"))
+}
@@ -0,0 +1,124 @@
+package crypt
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NewHmac 创建流式计算HMAC的hash.Hash
+func NewHmac(h Hash, key []byte) (hash.Hash, error) {
+	newHash, err := h.macHash()
+	if err != nil {
+		return nil, err
+	}
+	return hmac.New(newHash, key), nil
+}
+
+// Hmac 计算数据的HMAC
+func Hmac(h Hash, key, data []byte) ([]byte, error) {
+	mac, err := NewHmac(h, key)
+	if err != nil {
+		return nil, err
+	}
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// HmacEncode 计算数据的HMAC, 并按指定方式编码
+func HmacEncode(h Hash, key, data []byte, enc Encoding) (string, error) {
+	sum, err := Hmac(h, key, data)
+	if err != nil {
+		return "", err
+	}
+	return enc.EncodeToString(sum)
+}
+
+// HmacVerify 使用常量时间比较校验HMAC, 不一致时返回ErrVerification
+func HmacVerify(h Hash, key, data, mac []byte) error {
+	sum, err := Hmac(h, key, data)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(sum, mac) {
+		return ErrVerification
+	}
+	return nil
+}
+
+// HmacVerifyString 校验编码后的HMAC, hex不区分大小写
+func HmacVerifyString(h Hash, key, data []byte, mac string, enc Encoding) error {
+	raw, err := enc.DecodeString(mac)
+	if errors.Is(err, ErrInvalidEncoding) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("%w: %s", ErrVerification, err.Error())
+	}
+	return HmacVerify(h, key, data, raw)
+}
+
+// CanonicalString 将参数按key升序拼接为 k1=v1&k2=v2 形式的待签名字符串
+// 值为空的参数和excludes中的key(如sign)不参与拼接, 值不做url编码
+// 同一个key有多个值时, 按原有顺序分别拼接
+func CanonicalString(params url.Values, excludes ...string) string {
+	keys := canonicalKeys(params, excludes)
+	var sb strings.Builder
+	for _, k := range keys {
+		for _, v := range params[k] {
+			if v == "" {
+				continue
+			}
+			if sb.Len() > 0 {
+				sb.WriteByte('&')
+			}
+			sb.WriteString(k)
+			sb.WriteByte('=')
+			sb.WriteString(v)
+		}
+	}
+	return sb.String()
+}
+
+// CanonicalMap 与CanonicalString相同, 参数为map, 值使用fmt.Sprint转为字符串, nil值不参与拼接
+func CanonicalMap[V any](params map[string]V, excludes ...string) string {
+	values := make(url.Values, len(params))
+	for k, v := range params {
+		if any(v) == nil {
+			continue
+		}
+		values.Set(k, fmt.Sprint(v))
+	}
+	return CanonicalString(values, excludes...)
+}
+
+// canonicalKeys 返回排序后需要参与拼接的key
+func canonicalKeys(params url.Values, excludes []string) []string {
+	skip := make(map[string]bool, len(excludes))
+	for _, e := range excludes {
+		skip[e] = true
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if !skip[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HmacParams 对参数的待签名字符串计算HMAC
+//
+//	sign, err := HmacParams(SHA256, secret, form, HEXUPPER, "sign")
+func HmacParams(h Hash, key []byte, params url.Values, enc Encoding, excludes ...string) (string, error) {
+	return HmacEncode(h, key, []byte(CanonicalString(params, excludes...)), enc)
+}
+
+// HmacVerifyParams 校验参数的HMAC签名, 不一致时返回ErrVerification
+func HmacVerifyParams(h Hash, key []byte, params url.Values, sign string, enc Encoding, excludes ...string) error {
+	return HmacVerifyString(h, key, []byte(CanonicalString(params, excludes...)), sign, enc)
+}
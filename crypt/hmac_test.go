@@ -0,0 +1,62 @@
+package crypt
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHmac(t *testing.T) {
+	// rfc 4231 test case 2
+	key, data := []byte("Jefe"), []byte("what do ya want for nothing?")
+	expect := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+
+	out, err := HmacEncode(SHA256, key, data, HEX)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, out)
+	sum, err := Hmac(SM3, key, data)
+	assert.Nil(t, err)
+	assert.Equal(t, HmacSm3(key, data), sum)
+
+	assert.Nil(t, HmacVerifyString(SHA256, key, data, strings.ToUpper(expect), HEX))
+	assert.True(t, errors.Is(HmacVerifyString(SHA256, key, data, expect[2:], HEX), ErrVerification))
+	assert.True(t, errors.Is(HmacVerifyString(SHA256, key, data, "not hex", HEX), ErrVerification))
+	assert.True(t, errors.Is(HmacVerify(SHA256, []byte("jefe"), data, sum), ErrVerification))
+
+	_, err = Hmac(CRC32, key, data)
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+}
+
+func TestCanonicalString(t *testing.T) {
+	params := url.Values{
+		"nonce":  {"abc"},
+		"appid":  {"wx1"},
+		"amount": {"100"},
+		"remark": {""},
+		"sign":   {"xxx"},
+	}
+	assert.Equal(t, "amount=100&appid=wx1&nonce=abc", CanonicalString(params, "sign"))
+	assert.Equal(t, "amount=100&appid=wx1&nonce=abc&sign=xxx", CanonicalString(params))
+	assert.Equal(t, "a=1&a=2&b=3", CanonicalString(url.Values{"b": {"3"}, "a": {"1", "2"}}))
+	assert.Equal(t, "", CanonicalString(nil))
+
+	m := map[string]any{"nonce": "abc", "appid": "wx1", "amount": 100, "remark": nil, "sign": "xxx"}
+	assert.Equal(t, "amount=100&appid=wx1&nonce=abc", CanonicalMap(m, "sign"))
+
+	// openssl dgst -sha256 -hmac secret / openssl dgst -sm3 -hmac secret
+	secret := []byte("secret")
+	sign, err := HmacParams(SHA256, secret, params, HEXUPPER, "sign")
+	assert.Nil(t, err)
+	assert.Equal(t, strings.ToUpper("932524ab0272d2970c50f9125132ff1650a3f33b1c865a2d439d532f9f72bb2e"), sign)
+	sign, err = HmacParams(SM3, secret, params, HEX, "sign")
+	assert.Nil(t, err)
+	assert.Equal(t, "418fb90d549aaad26dd9bd26ef9e8d212d82269e17fde98b4ef1f8b874b6f65a", sign)
+
+	params.Set("sign", sign)
+	assert.Nil(t, HmacVerifyParams(SM3, secret, params, params.Get("sign"), HEX, "sign"))
+	params.Set("amount", "1000")
+	assert.True(t, errors.Is(HmacVerifyParams(SM3, secret, params, sign, HEX, "sign"), ErrVerification))
+}
@@ -0,0 +1,229 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// jwe key management algorithm
+type JWEAlgorithm string
+
+const (
+	//rsa OAEP with sha1, only for compatibility with old systems
+	RSAOAEP JWEAlgorithm = "RSA-OAEP"
+	//rsa OAEP with sha256
+	RSAOAEP256 JWEAlgorithm = "RSA-OAEP-256"
+	//direct use of a shared symmetric key as the content encryption key
+	DIR JWEAlgorithm = "dir"
+)
+
+// jwe content encryption algorithm
+type JWEEncryption string
+
+const (
+	//aes-128-gcm, content encryption key length 16
+	A128GCM JWEEncryption = "A128GCM"
+	//aes-256-gcm, content encryption key length 32
+	A256GCM JWEEncryption = "A256GCM"
+)
+
+// jwe中GCM的tag长度
+const jweTagSize = 16
+
+// keySize 返回内容加密密钥(CEK)的长度
+func (e JWEEncryption) keySize() int {
+	switch e {
+	case A128GCM:
+		return 16
+	case A256GCM:
+		return 32
+	default:
+		return 0
+	}
+}
+
+// JWEHeader JWE头部(protected header)
+type JWEHeader struct {
+	Algorithm   JWEAlgorithm  `json:"alg"`
+	Encryption  JWEEncryption `json:"enc"`
+	Type        string        `json:"typ,omitempty"`
+	ContentType string        `json:"cty,omitempty"`
+	KeyID       string        `json:"kid,omitempty"`
+	// 不支持压缩, 不为空时解密失败
+	Compression string `json:"zip,omitempty"`
+	// 不支持任何扩展头, 不为空时解密失败
+	Critical []string `json:"crit,omitempty"`
+}
+
+// EncryptJWE 生成compact格式的JWE:
+// base64url(header).base64url(encrypted key).base64url(iv).base64url(ciphertext).base64url(tag)
+// key的类型由header.Algorithm决定:
+//   - RSA-OAEP/RSA-OAEP-256: *rsa.PublicKey, 随机生成CEK并使用公钥加密
+//   - dir: []byte, 直接作为CEK, 长度必须与header.Encryption一致(16或32)
+func EncryptJWE(header JWEHeader, plaintext []byte, key any) (string, error) {
+	if header.Compression != "" {
+		return "", fmt.Errorf("%w: jwe compression %s", ErrInvalidAlgorithm, header.Compression)
+	}
+	size := header.Encryption.keySize()
+	if size == 0 {
+		return "", fmt.Errorf("%w: jwe encryption %s", ErrInvalidAlgorithm, header.Encryption)
+	}
+
+	var cek, encryptedKey []byte
+	switch header.Algorithm {
+	case RSAOAEP, RSAOAEP256:
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return "", keyTypeError("*rsa.PublicKey", key)
+		}
+		cek = make([]byte, size)
+		if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+			return "", err
+		}
+		var err error
+		if encryptedKey, err = PublicEncryptOAEP(k, cek, header.Algorithm.hash(), nil); err != nil {
+			return "", err
+		}
+	case DIR:
+		k, ok := key.([]byte)
+		if !ok {
+			return "", keyTypeError("[]byte", key)
+		}
+		if len(k) != size {
+			return "", fmt.Errorf("%w: %s key must be %d bytes", ErrInvalidKey, header.Encryption, size)
+		}
+		cek = k
+	default:
+		return "", fmt.Errorf("%w: jwe algorithm %s", ErrInvalidAlgorithm, header.Algorithm)
+	}
+
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(h)
+	iv := make([]byte, GCMNonceSize)
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+	out, err := gcmCrypt(AES, plaintext, cek, iv, []byte(protected), true)
+	if err != nil {
+		return "", err
+	}
+
+	tag := out[len(out)-jweTagSize:]
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(out[:len(out)-jweTagSize]),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// ParseJWEHeader 读取JWE的头部但不解密, 用于根据kid选择解密的密钥
+func ParseJWEHeader(token string) (*JWEHeader, error) {
+	header, _, err := splitJWE(token)
+	return header, err
+}
+
+// DecryptJWE 解密compact格式的JWE并返回头部和明文
+// 头部中的alg必须与alg一致; key为RSA-OAEP*算法的*rsa.PrivateKey或dir算法的[]byte
+// 密钥不匹配或数据被篡改时返回ErrAuthentication, 格式错误时返回ErrInvalidFormat
+func DecryptJWE(token string, alg JWEAlgorithm, key any) (*JWEHeader, []byte, error) {
+	header, raw, err := splitJWE(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Algorithm != alg {
+		return nil, nil, fmt.Errorf("%w: unexpected jwe algorithm %s", ErrAuthentication, header.Algorithm)
+	}
+	if len(header.Critical) > 0 {
+		return nil, nil, fmt.Errorf("%w: unsupported critical header %v", ErrAuthentication, header.Critical)
+	}
+	if header.Compression != "" {
+		return nil, nil, fmt.Errorf("%w: jwe compression %s", ErrInvalidAlgorithm, header.Compression)
+	}
+	size := header.Encryption.keySize()
+	if size == 0 {
+		return nil, nil, fmt.Errorf("%w: jwe encryption %s", ErrInvalidAlgorithm, header.Encryption)
+	}
+
+	encryptedKey, iv, ciphertext, tag := raw[0], raw[1], raw[2], raw[3]
+	if len(iv) != GCMNonceSize || len(tag) != jweTagSize {
+		return nil, nil, fmt.Errorf("%w: invalid jwe iv or tag length", ErrInvalidFormat)
+	}
+
+	var cek []byte
+	switch alg {
+	case RSAOAEP, RSAOAEP256:
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, keyTypeError("*rsa.PrivateKey", key)
+		}
+		if len(encryptedKey) != k.Size() {
+			return nil, nil, ErrAuthentication
+		}
+		if cek, err = PrivateDecryptOAEP(k, encryptedKey, alg.hash(), nil); err != nil || len(cek) != size {
+			return nil, nil, ErrAuthentication
+		}
+	case DIR:
+		k, ok := key.([]byte)
+		if !ok {
+			return nil, nil, keyTypeError("[]byte", key)
+		}
+		if len(encryptedKey) != 0 {
+			return nil, nil, fmt.Errorf("%w: jwe encrypted key must be empty with dir", ErrInvalidFormat)
+		}
+		if len(k) != size {
+			return nil, nil, fmt.Errorf("%w: %s key must be %d bytes", ErrInvalidKey, header.Encryption, size)
+		}
+		cek = k
+	default:
+		return nil, nil, fmt.Errorf("%w: jwe algorithm %s", ErrInvalidAlgorithm, alg)
+	}
+
+	protected := token[:strings.IndexByte(token, '.')]
+	plaintext, err := gcmCrypt(AES, append(ciphertext, tag...), cek, iv, []byte(protected), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, plaintext, nil
+}
+
+// hash 返回RSA-OAEP使用的hash算法
+func (a JWEAlgorithm) hash() Hash {
+	if a == RSAOAEP {
+		return SHA1
+	}
+	return SHA256
+}
+
+// splitJWE 拆分并解码compact格式的JWE, 返回头部和其余4个部分
+func splitJWE(token string) (*JWEHeader, [4][]byte, error) {
+	var raw [4][]byte
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, raw, fmt.Errorf("%w: jwe must have 5 parts", ErrInvalidFormat)
+	}
+	h, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, raw, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	header := &JWEHeader{}
+	if err = json.Unmarshal(h, header); err != nil {
+		return nil, raw, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	for i, part := range parts[1:] {
+		if raw[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return nil, raw, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+		}
+	}
+	return header, raw, nil
+}
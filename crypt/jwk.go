@@ -0,0 +1,449 @@
+package crypt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/goccy/go-json"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// jwk中sm2曲线的名称, 未在IANA注册
+const jwkCurveSM2 = "SM2"
+
+// JWK JSON Web Key(RFC 7517), 所有的二进制字段都是base64url编码(无填充)
+//
+// 支持的密钥类型:
+//   - RSA: *rsa.PublicKey, *rsa.PrivateKey
+//   - EC: *ecdsa.PublicKey, *ecdsa.PrivateKey(P-256/P-384/P-521), *sm2.PublicKey, *sm2.PrivateKey(crv为SM2)
+//   - OKP: ed25519.PublicKey, ed25519.PrivateKey
+//   - oct: []byte
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+
+	// RSA
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// EC/OKP
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+
+	// RSA/EC/OKP的私钥
+	D string `json:"d,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// NewJWK 将密钥转换为JWK, kid为空时使用RFC 7638的SHA-256 thumbprint
+func NewJWK(key any, kid string) (*JWK, error) {
+	j := &JWK{}
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		j.setRSA(k)
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, fmt.Errorf("%w: multi-prime rsa key is not supported", ErrInvalidKey)
+		}
+		j.setRSA(&k.PublicKey)
+		k.Precompute()
+		j.D = b64Int(k.D, 0)
+		j.P = b64Int(k.Primes[0], 0)
+		j.Q = b64Int(k.Primes[1], 0)
+		j.DP = b64Int(k.Precomputed.Dp, 0)
+		j.DQ = b64Int(k.Precomputed.Dq, 0)
+		j.QI = b64Int(k.Precomputed.Qinv, 0)
+	case *ecdsa.PublicKey:
+		if err := j.setEC(k.Curve, k.X, k.Y); err != nil {
+			return nil, err
+		}
+	case *ecdsa.PrivateKey:
+		if err := j.setEC(k.Curve, k.X, k.Y); err != nil {
+			return nil, err
+		}
+		j.D = b64Int(k.D, curveSize(k.Curve))
+	case *sm2.PublicKey:
+		if err := j.setEC(k.Curve, k.X, k.Y); err != nil {
+			return nil, err
+		}
+	case *sm2.PrivateKey:
+		if err := j.setEC(k.Curve, k.X, k.Y); err != nil {
+			return nil, err
+		}
+		j.D = b64Int(k.D, curveSize(k.Curve))
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: ed25519 public key length %d", ErrInvalidKey, len(k))
+		}
+		j.KeyType, j.Curve = "OKP", "Ed25519"
+		j.X = base64.RawURLEncoding.EncodeToString(k)
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("%w: ed25519 private key length %d", ErrInvalidKey, len(k))
+		}
+		j.KeyType, j.Curve = "OKP", "Ed25519"
+		j.X = base64.RawURLEncoding.EncodeToString(k.Public().(ed25519.PublicKey))
+		j.D = base64.RawURLEncoding.EncodeToString(k.Seed())
+	case []byte:
+		j.KeyType = "oct"
+		j.K = base64.RawURLEncoding.EncodeToString(k)
+	default:
+		return nil, keyTypeError("rsa, ecdsa, sm2, ed25519 key or []byte", key)
+	}
+
+	if kid == "" {
+		tp, err := j.Thumbprint(SHA256)
+		if err != nil {
+			return nil, err
+		}
+		kid = base64.RawURLEncoding.EncodeToString(tp)
+	}
+	j.KeyID = kid
+	return j, nil
+}
+
+// ParseJWK 读取JSON格式的JWK并检查密钥是否有效
+func ParseJWK(data []byte) (*JWK, error) {
+	j := &JWK{}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	if _, err := j.Key(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// IsPrivate 是否包含私钥或对称密钥
+func (j *JWK) IsPrivate() bool {
+	return j.D != "" || j.K != ""
+}
+
+// Public 返回只包含公钥部分的JWK, 用于发布给对方; oct类型没有公钥, 返回nil
+func (j *JWK) Public() *JWK {
+	if j.KeyType == "oct" {
+		return nil
+	}
+	return &JWK{
+		KeyType:   j.KeyType,
+		KeyID:     j.KeyID,
+		Use:       j.Use,
+		Algorithm: j.Algorithm,
+		Curve:     j.Curve,
+		N:         j.N,
+		E:         j.E,
+		X:         j.X,
+		Y:         j.Y,
+	}
+}
+
+// Key 将JWK转换为密钥, 类型与NewJWK的参数对应, 包含私钥时返回私钥
+func (j *JWK) Key() (any, error) {
+	switch j.KeyType {
+	case "RSA":
+		return j.rsaKey()
+	case "EC":
+		return j.ecKey()
+	case "OKP":
+		return j.okpKey()
+	case "oct":
+		k, err := b64Bytes("k", j.K)
+		if err != nil {
+			return nil, err
+		}
+		if len(k) == 0 {
+			return nil, fmt.Errorf("%w: empty oct key", ErrInvalidKey)
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("%w: jwk key type %q", ErrInvalidKeyFormat, j.KeyType)
+	}
+}
+
+// Thumbprint 计算RFC 7638的thumbprint, 即必需字段按字典序排列的JSON的hash
+func (j *JWK) Thumbprint(h Hash) ([]byte, error) {
+	var s string
+	switch j.KeyType {
+	case "RSA":
+		s = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, j.E, j.N)
+	case "EC":
+		s = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, j.Curve, j.X, j.Y)
+	case "OKP":
+		s = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, j.Curve, j.X)
+	case "oct":
+		s = fmt.Sprintf(`{"k":%q,"kty":"oct"}`, j.K)
+	default:
+		return nil, fmt.Errorf("%w: jwk key type %q", ErrInvalidKeyFormat, j.KeyType)
+	}
+	return Digest(h, []byte(s))
+}
+
+// setRSA 设置RSA公钥字段
+func (j *JWK) setRSA(key *rsa.PublicKey) {
+	j.KeyType = "RSA"
+	j.N = b64Int(key.N, 0)
+	j.E = b64Int(big.NewInt(int64(key.E)), 0)
+}
+
+// setEC 设置EC公钥字段, 坐标按曲线长度左侧补0
+func (j *JWK) setEC(curve elliptic.Curve, x, y *big.Int) error {
+	crv, err := jwkCurveName(curve)
+	if err != nil {
+		return err
+	}
+	size := curveSize(curve)
+	j.KeyType, j.Curve = "EC", crv
+	j.X, j.Y = b64Int(x, size), b64Int(y, size)
+	return nil
+}
+
+// rsaKey 转换RSA密钥, 私钥必须包含p和q
+func (j *JWK) rsaKey() (any, error) {
+	n, err := b64BigInt("n", j.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := b64BigInt("e", j.E)
+	if err != nil {
+		return nil, err
+	}
+	if n.Sign() == 0 || !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("%w: invalid rsa modulus or exponent", ErrInvalidKey)
+	}
+	pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+	if j.D == "" {
+		return pub, nil
+	}
+
+	key := &rsa.PrivateKey{PublicKey: *pub}
+	if key.D, err = b64BigInt("d", j.D); err != nil {
+		return nil, err
+	}
+	if j.P == "" || j.Q == "" {
+		return nil, fmt.Errorf("%w: rsa private key without p and q", ErrInvalidKey)
+	}
+	p, err := b64BigInt("p", j.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := b64BigInt("q", j.Q)
+	if err != nil {
+		return nil, err
+	}
+	key.Primes = []*big.Int{p, q}
+	if err = key.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err.Error())
+	}
+	key.Precompute()
+	return key, nil
+}
+
+// ecKey 转换ecdsa或sm2密钥, 检查坐标是否在曲线上以及私钥是否与公钥匹配
+func (j *JWK) ecKey() (any, error) {
+	var curve elliptic.Curve
+	switch j.Curve {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	case jwkCurveSM2:
+		curve = sm2.P256Sm2()
+	default:
+		return nil, fmt.Errorf("%w: jwk curve %q", ErrInvalidKeyFormat, j.Curve)
+	}
+
+	size := curveSize(curve)
+	x, err := b64Coordinate("x", j.X, size)
+	if err != nil {
+		return nil, err
+	}
+	y, err := b64Coordinate("y", j.Y, size)
+	if err != nil {
+		return nil, err
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("%w: point not on curve %s", ErrInvalidKey, j.Curve)
+	}
+
+	var d *big.Int
+	if j.D != "" {
+		if d, err = b64Coordinate("d", j.D, size); err != nil {
+			return nil, err
+		}
+		if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+			return nil, fmt.Errorf("%w: invalid private key", ErrInvalidKey)
+		}
+		if px, py := curve.ScalarBaseMult(d.Bytes()); px.Cmp(x) != 0 || py.Cmp(y) != 0 {
+			return nil, fmt.Errorf("%w: private key does not match public key", ErrInvalidKey)
+		}
+	}
+
+	if j.Curve == jwkCurveSM2 {
+		pub := sm2.PublicKey{Curve: curve, X: x, Y: y}
+		if d == nil {
+			return &pub, nil
+		}
+		return &sm2.PrivateKey{PublicKey: pub, D: d}, nil
+	}
+	pub := ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	if d == nil {
+		return &pub, nil
+	}
+	return &ecdsa.PrivateKey{PublicKey: pub, D: d}, nil
+}
+
+// okpKey 转换ed25519密钥
+func (j *JWK) okpKey() (any, error) {
+	if j.Curve != "Ed25519" {
+		return nil, fmt.Errorf("%w: jwk curve %q", ErrInvalidKeyFormat, j.Curve)
+	}
+	x, err := b64Bytes("x", j.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: ed25519 public key length %d", ErrInvalidKey, len(x))
+	}
+	if j.D == "" {
+		return ed25519.PublicKey(x), nil
+	}
+	d, err := b64Bytes("d", j.D)
+	if err != nil {
+		return nil, err
+	}
+	if len(d) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: ed25519 private key length %d", ErrInvalidKey, len(d))
+	}
+	key := ed25519.NewKeyFromSeed(d)
+	if !key.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		return nil, fmt.Errorf("%w: private key does not match public key", ErrInvalidKey)
+	}
+	return key, nil
+}
+
+// JWKS JSON Web Key Set, 可以直接序列化为JSON发布
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// ParseJWKS 读取JSON格式的JWK Set, 任何一个密钥无效时返回错误
+func ParseJWKS(data []byte) (*JWKS, error) {
+	set := &JWKS{}
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	for _, j := range set.Keys {
+		if j == nil {
+			return nil, fmt.Errorf("%w: null jwk", ErrInvalidFormat)
+		}
+		if _, err := j.Key(); err != nil {
+			return nil, fmt.Errorf("jwk %q: %w", j.KeyID, err)
+		}
+	}
+	return set, nil
+}
+
+// Lookup 根据kid查找JWK, 找不到时返回ErrKeyNotFound
+func (s *JWKS) Lookup(kid string) (*JWK, error) {
+	for _, j := range s.Keys {
+		if j.KeyID == kid {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+}
+
+// Key 根据kid查找并转换密钥, 可以直接用于VerifyJWS, ParseJWT等
+func (s *JWKS) Key(kid string) (any, error) {
+	j, err := s.Lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	return j.Key()
+}
+
+// Public 返回只包含公钥的JWK Set, oct类型的密钥被忽略
+func (s *JWKS) Public() *JWKS {
+	pub := &JWKS{Keys: []*JWK{}}
+	for _, j := range s.Keys {
+		if p := j.Public(); p != nil {
+			pub.Keys = append(pub.Keys, p)
+		}
+	}
+	return pub
+}
+
+// jwkCurveName 返回曲线在jwk中的名称
+func jwkCurveName(curve elliptic.Curve) (string, error) {
+	if curve.Params() == sm2.P256Sm2().Params() {
+		return jwkCurveSM2, nil
+	}
+	switch curve {
+	case elliptic.P256():
+		return "P-256", nil
+	case elliptic.P384():
+		return "P-384", nil
+	case elliptic.P521():
+		return "P-521", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported curve %s", ErrInvalidKey, curve.Params().Name)
+	}
+}
+
+// b64Int 大整数转换为base64url, size大于0时左侧补0到size字节
+func b64Int(n *big.Int, size int) string {
+	if size == 0 {
+		size = (n.BitLen() + 7) / 8
+	}
+	return base64.RawURLEncoding.EncodeToString(n.FillBytes(make([]byte, size)))
+}
+
+// b64Bytes 解码jwk中的base64url字段
+func b64Bytes(name, s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("%w: missing jwk member %s", ErrInvalidKey, name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: jwk member %s: %s", ErrInvalidFormat, name, err.Error())
+	}
+	return b, nil
+}
+
+// b64BigInt 解码jwk中的大整数字段
+func b64BigInt(name, s string) (*big.Int, error) {
+	b, err := b64Bytes(name, s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// b64Coordinate 解码EC的坐标或私钥, RFC 7518要求长度必须等于曲线长度
+func b64Coordinate(name, s string, size int) (*big.Int, error) {
+	b, err := b64Bytes(name, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != size {
+		return nil, fmt.Errorf("%w: jwk member %s must be %d bytes", ErrInvalidKey, name, size)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,165 @@
+package crypt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+func TestJWKThumbprint(t *testing.T) {
+	// RFC 7638 3.1
+	j := &JWK{
+		KeyType: "RSA",
+		N:       "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:       "AQAB",
+		Use:     "sig",
+	}
+	tp, err := j.Thumbprint(SHA256)
+	assert.Nil(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", base64.RawURLEncoding.EncodeToString(tp))
+
+	// RFC 7520 3.1, 3.4 and RFC 8037 A.1
+	for data, want := range map[string]string{
+		`{"kty":"EC","kid":"bilbo.baggins@hobbiton.example","use":"sig","crv":"P-521",
+		"x":"AHKZLLOsCOzz5cY97ewNUajB957y-C-U88c3v13nmGZx6sYl_oJXu9A5RkTKqjqvjyekWF-7ytDyRXYgCF5cj0Kt",
+		"y":"AdymlHvOiLxXkEhayXQnNCvDX4h9htZaCJN34kfmC6pV5OhQHiraVySsUdaQkAgDPrwQrJmbnX9cwlGfP-HqHZR1",
+		"d":"AAhRON2r9cqXX1hg-RoI6R1tX5p2rUAYdmpHZoC1XNM56KtscrX6zbKipQrCW9CGZH3T4ubpnoTKLDYJ_fF3_rJt"}`: "747ae2dd2003664aeeb21e4753fe7402846170a16bc8df8f23a8cf06d3cbe793",
+		`{"kty":"RSA","kid":"bilbo.baggins@hobbiton.example","use":"sig",
+		"n":"n4EPtAOCc9AlkeQHPzHStgAbgs7bTZLwUBZdR8_KuKPEHLd4rHVTeT-O-XV2jRojdNhxJWTDvNd7nqQ0VEiZQHz_AJmSCpMaJMRBSFKrKb2wqVwGU_NsYOYL-QtiWN2lbzcEe6XC0dApr5ydQLrHqkHHig3RBordaZ6Aj-oBHqFEHYpPe7Tpe-OfVfHd1E6cS6M1FZcD1NNLYD5lFHpPI9bTwJlsde3uhGqC0ZCuEHg8lhzwOHrtIQbS0FVbb9k3-tVTU4fg_3L_vniUFAKwuCLqKnS2BYwdq_mzSnbLY7h_qixoR7jig3__kRhuaxwUkRz5iaiQkqgc5gHdrNP5zw",
+		"e":"AQAB"}`: "f63838e96077ad1fc01c3f8405774dedc0641f558ebb4b40dccf5f9b6d66a932",
+		`{"kty":"OKP","crv":"Ed25519",
+		"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+		"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`: "90facafea9b1556698540f70c0117a22ea37bd5cf3ed3c47093c1707282b4b89",
+	} {
+		j, err := ParseJWK([]byte(data))
+		if !assert.Nil(t, err, "%s", data) {
+			continue
+		}
+		tp, err := j.Thumbprint(SHA256)
+		assert.Nil(t, err)
+		assert.Equal(t, want, hex.EncodeToString(tp))
+	}
+}
+
+func TestJWKKeys(t *testing.T) {
+	ecKey, _ := GenerateECDSAKey(elliptic.P384())
+	sm2Key, _ := GenerateSM2Key()
+	_, edKey, _ := ed25519.GenerateKey(nil)
+
+	keys := []any{
+		testRSAKey, &testRSAKey.PublicKey,
+		ecKey, &ecKey.PublicKey,
+		sm2Key, &sm2Key.PublicKey,
+		edKey, edKey.Public(),
+		[]byte("0123456789abcdef"),
+	}
+	for _, key := range keys {
+		j, err := NewJWK(key, "")
+		if !assert.Nil(t, err, "%T", key) {
+			continue
+		}
+		tp, _ := j.Thumbprint(SHA256)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(tp), j.KeyID)
+
+		data, err := json.Marshal(j)
+		assert.Nil(t, err)
+		parsed, err := ParseJWK(data)
+		if !assert.Nil(t, err, "%T", key) {
+			continue
+		}
+		got, err := parsed.Key()
+		assert.Nil(t, err)
+		assert.Equal(t, key, got, "%T", key)
+
+		// the public jwk has the same thumbprint
+		if pub := j.Public(); pub != nil {
+			assert.False(t, pub.IsPrivate())
+			ptp, _ := pub.Thumbprint(SHA256)
+			assert.Equal(t, tp, ptp)
+		}
+	}
+
+	j, _ := NewJWK(&sm2Key.PublicKey, "sm2")
+	assert.Equal(t, "SM2", j.Curve)
+	assert.Equal(t, "sm2", j.KeyID)
+}
+
+func TestJWKS(t *testing.T) {
+	ecKey, _ := GenerateECDSAKey(elliptic.P256())
+	rsaJWK, _ := NewJWK(testRSAKey, "rsa-2024")
+	ecJWK, _ := NewJWK(ecKey, "ec-2024")
+	hsJWK, _ := NewJWK(make([]byte, 32), "hs")
+	set := &JWKS{Keys: []*JWK{rsaJWK, ecJWK, hsJWK}}
+
+	// only public keys are served
+	data, err := json.Marshal(set.Public())
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), `"d":`)
+
+	served, err := ParseJWKS(data)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(served.Keys))
+	_, err = served.Lookup("hs")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	// verify tokens with the key selected by kid
+	token, err := SignJWS(JWSHeader{Algorithm: ES256, KeyID: "ec-2024"}, []byte("payload"), ecKey)
+	assert.Nil(t, err)
+	header, _ := ParseJWSHeader(token)
+	key, err := served.Key(header.KeyID)
+	assert.Nil(t, err)
+	_, payload, err := VerifyJWS(token, ES256, key)
+	assert.Nil(t, err)
+	assert.Equal(t, "payload", string(payload))
+
+	key, err = served.Key("rsa-2024")
+	assert.Nil(t, err)
+	assert.Equal(t, &testRSAKey.PublicKey, key.(*rsa.PublicKey))
+}
+
+func TestJWKErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"kty":"EC","crv":"P-256","x":"AAAA","y":"AAAA"}`,
+		`{"kty":"EC","crv":"secp256k1","x":"AAAA","y":"AAAA"}`,
+		`{"kty":"OKP","crv":"X448","x":"AAAA"}`,
+		`{"kty":"RSA","n":"AAAA"}`,
+		`{"kty":"oct","k":"not base64!"}`,
+		`{"kty":"DSA"}`,
+		`{"kty":`,
+	} {
+		_, err := ParseJWK([]byte(data))
+		assert.NotNil(t, err, "%s", data)
+	}
+
+	// point not on curve
+	key, _ := GenerateECDSAKey(elliptic.P256())
+	j, _ := NewJWK(&key.PublicKey, "")
+	j.Y = j.X
+	_, err := j.Key()
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	// private key does not match
+	other, _ := GenerateECDSAKey(elliptic.P256())
+	j, _ = NewJWK(key, "")
+	j.D = b64Int(other.D, 32)
+	_, err = j.Key()
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	_, err = NewJWK(&ecdsa.PublicKey{Curve: elliptic.P224(), X: key.X, Y: key.Y}, "")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = NewJWK(sm2.PublicKey{}, "")
+	var typeErr *KeyTypeError
+	assert.True(t, errors.As(err, &typeErr))
+
+	_, err = ParseJWKS([]byte(`{"keys":[{"kty":"oct","k":"AAAA"},{"kty":"EC","crv":"P-256"}]}`))
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
@@ -0,0 +1,269 @@
+package crypt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// jws signature algorithm
+type JWSAlgorithm string
+
+const (
+	//hmac-sha256, key is []byte at least 32 bytes
+	HS256 JWSAlgorithm = "HS256"
+	//hmac-sha384, key is []byte at least 48 bytes
+	HS384 JWSAlgorithm = "HS384"
+	//hmac-sha512, key is []byte at least 64 bytes
+	HS512 JWSAlgorithm = "HS512"
+	//rsa PKCS#1 v1.5 with sha256
+	RS256 JWSAlgorithm = "RS256"
+	//rsa PKCS#1 v1.5 with sha384
+	RS384 JWSAlgorithm = "RS384"
+	//rsa PKCS#1 v1.5 with sha512
+	RS512 JWSAlgorithm = "RS512"
+	//rsa PSS with sha256
+	PS256 JWSAlgorithm = "PS256"
+	//rsa PSS with sha384
+	PS384 JWSAlgorithm = "PS384"
+	//rsa PSS with sha512
+	PS512 JWSAlgorithm = "PS512"
+	//ecdsa P-256 with sha256
+	ES256 JWSAlgorithm = "ES256"
+	//ecdsa P-384 with sha384
+	ES384 JWSAlgorithm = "ES384"
+	//ecdsa P-521 with sha512
+	ES512 JWSAlgorithm = "ES512"
+	//ed25519
+	EdDSA JWSAlgorithm = "EdDSA"
+	//sm2 with sm3 and the default uid, signature is raw r|s of 64 bytes, not registered in IANA
+	SM2SM3 JWSAlgorithm = "SM2SM3"
+)
+
+// hash 返回签名使用的hash算法
+func (a JWSAlgorithm) hash() Hash {
+	switch a {
+	case HS256, RS256, PS256, ES256:
+		return SHA256
+	case HS384, RS384, PS384, ES384:
+		return SHA384
+	case HS512, RS512, PS512, ES512:
+		return SHA512
+	case SM2SM3:
+		return SM3
+	default:
+		return ""
+	}
+}
+
+// JWSHeader JWS头部
+type JWSHeader struct {
+	Algorithm   JWSAlgorithm `json:"alg"`
+	Type        string       `json:"typ,omitempty"`
+	ContentType string       `json:"cty,omitempty"`
+	KeyID       string       `json:"kid,omitempty"`
+	// 不支持任何扩展头, 不为空时校验失败
+	Critical []string `json:"crit,omitempty"`
+}
+
+// SignJWS 生成compact格式的JWS: base64url(header).base64url(payload).base64url(signature)
+// key的类型由算法决定:
+//   - HS256/HS384/HS512: []byte, 长度不能小于hash长度
+//   - RS*/PS*: *rsa.PrivateKey
+//   - ES256/ES384/ES512: *ecdsa.PrivateKey, 曲线分别为P-256/P-384/P-521
+//   - EdDSA: ed25519.PrivateKey
+//   - SM2SM3: *sm2.PrivateKey
+func SignJWS(header JWSHeader, payload []byte, key any) (string, error) {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := jwsSign(header.Algorithm, []byte(input), key)
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// ParseJWSHeader 读取JWS的头部但不校验签名, 用于根据kid选择校验的密钥
+func ParseJWSHeader(token string) (*JWSHeader, error) {
+	header, _, _, err := splitJWS(token)
+	return header, err
+}
+
+// VerifyJWS 校验compact格式的JWS并返回头部和payload
+// 头部中的alg必须与alg一致, 防止使用公钥作为hmac密钥等算法混淆攻击
+// key为签名私钥对应的公钥, HS*算法为[]byte; 签名错误时返回ErrVerification, 格式错误时返回ErrInvalidFormat
+func VerifyJWS(token string, alg JWSAlgorithm, key any) (*JWSHeader, []byte, error) {
+	header, payload, sig, err := splitJWS(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Algorithm != alg {
+		return nil, nil, fmt.Errorf("%w: unexpected jws algorithm %s", ErrVerification, header.Algorithm)
+	}
+	if len(header.Critical) > 0 {
+		return nil, nil, fmt.Errorf("%w: unsupported critical header %v", ErrVerification, header.Critical)
+	}
+	input := token[:strings.LastIndexByte(token, '.')]
+	if err = jwsVerify(alg, []byte(input), sig, key); err != nil {
+		return nil, nil, err
+	}
+	return header, payload, nil
+}
+
+// splitJWS 拆分并解码compact格式的JWS
+func splitJWS(token string) (*JWSHeader, []byte, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, fmt.Errorf("%w: jws must have 3 parts", ErrInvalidFormat)
+	}
+	var raw [3][]byte
+	for i, part := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+		}
+		raw[i] = b
+	}
+	header := &JWSHeader{}
+	if err := json.Unmarshal(raw[0], header); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	return header, raw[1], raw[2], nil
+}
+
+// jwsSign 使用alg对应的算法签名
+func jwsSign(alg JWSAlgorithm, input []byte, key any) ([]byte, error) {
+	h := alg.hash()
+	switch alg {
+	case HS256, HS384, HS512:
+		secret, err := jwsHmacKey(alg, key)
+		if err != nil {
+			return nil, err
+		}
+		return Hmac(h, secret, input)
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, keyTypeError("*rsa.PrivateKey", key)
+		}
+		if alg[0] == 'P' {
+			return SignPSS(k, input, h)
+		}
+		return SignPKCS1v15(k, input, h)
+	case ES256, ES384, ES512:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, keyTypeError("*ecdsa.PrivateKey", key)
+		}
+		if err := jwsCurve(alg, &k.PublicKey); err != nil {
+			return nil, err
+		}
+		return SignECDSA(k, input, h, P1363)
+	case EdDSA:
+		k, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, keyTypeError("ed25519.PrivateKey", key)
+		}
+		return SignEd25519(k, input)
+	case SM2SM3:
+		k, ok := key.(*sm2.PrivateKey)
+		if !ok {
+			return nil, keyTypeError("*sm2.PrivateKey", key)
+		}
+		r, s, err := sm2.Sm2Sign(k, input, nil, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	default:
+		return nil, fmt.Errorf("%w: jws algorithm %s", ErrInvalidAlgorithm, alg)
+	}
+}
+
+// jwsVerify 使用alg对应的算法校验签名
+func jwsVerify(alg JWSAlgorithm, input, sig []byte, key any) error {
+	h := alg.hash()
+	switch alg {
+	case HS256, HS384, HS512:
+		secret, err := jwsHmacKey(alg, key)
+		if err != nil {
+			return err
+		}
+		return HmacVerify(h, secret, input, sig)
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return keyTypeError("*rsa.PublicKey", key)
+		}
+		if alg[0] == 'P' {
+			return VerifyPSS(k, input, sig, h)
+		}
+		return VerifyPKCS1v15(k, input, sig, h)
+	case ES256, ES384, ES512:
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return keyTypeError("*ecdsa.PublicKey", key)
+		}
+		if err := jwsCurve(alg, k); err != nil {
+			return err
+		}
+		return VerifyECDSA(k, input, sig, h, P1363)
+	case EdDSA:
+		k, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return keyTypeError("ed25519.PublicKey", key)
+		}
+		return VerifyEd25519(k, input, sig)
+	case SM2SM3:
+		k, ok := key.(*sm2.PublicKey)
+		if !ok {
+			return keyTypeError("*sm2.PublicKey", key)
+		}
+		if len(sig) != 64 {
+			return ErrVerification
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !sm2.Sm2Verify(k, input, nil, r, s) {
+			return ErrVerification
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: jws algorithm %s", ErrInvalidAlgorithm, alg)
+	}
+}
+
+// jwsHmacKey 检查hmac密钥, RFC 7518要求密钥长度不小于hash长度
+func jwsHmacKey(alg JWSAlgorithm, key any) ([]byte, error) {
+	secret, ok := key.([]byte)
+	if !ok {
+		return nil, keyTypeError("[]byte", key)
+	}
+	newHash, _ := alg.hash().newHash()
+	if size := newHash().Size(); len(secret) < size {
+		return nil, fmt.Errorf("%w: %s key must be at least %d bytes", ErrInvalidKey, alg, size)
+	}
+	return secret, nil
+}
+
+// jwsCurve 检查ecdsa密钥的曲线是否与算法一致
+func jwsCurve(alg JWSAlgorithm, key *ecdsa.PublicKey) error {
+	want := map[JWSAlgorithm]int{ES256: 256, ES384: 384, ES512: 521}[alg]
+	if key.Curve.Params().BitSize != want {
+		return fmt.Errorf("%w: %s requires curve of %d bits", ErrInvalidKey, alg, want)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package crypt
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// NumericDate JWT中的时间, 从1970-01-01 UTC开始的秒数, 读取时兼容小数
+type NumericDate int64
+
+// NewNumericDate 转换时间为NumericDate
+func NewNumericDate(t time.Time) NumericDate {
+	return NumericDate(t.Unix())
+}
+
+// Time 转换为time.Time
+func (d NumericDate) Time() time.Time {
+	return time.Unix(int64(d), 0)
+}
+
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	var f float64
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+	*d = NumericDate(math.Floor(f))
+	return nil
+}
+
+// Audience JWT的aud, 可以是字符串或字符串数组, 只有一个值时输出为字符串
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var arr []string
+	if err := json.Unmarshal(b, &arr); err != nil {
+		return err
+	}
+	*a = arr
+	return nil
+}
+
+// contains 是否包含指定的值
+func (a Audience) contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisteredClaims RFC 7519中注册的声明, 自定义声明嵌入此结构体即可实现Claims
+//
+//	type UserClaims struct {
+//		RegisteredClaims
+//		UserID int64 `json:"uid"`
+//	}
+type RegisteredClaims struct {
+	Issuer    string      `json:"iss,omitempty"`
+	Subject   string      `json:"sub,omitempty"`
+	Audience  Audience    `json:"aud,omitempty"`
+	ExpiresAt NumericDate `json:"exp,omitempty"`
+	NotBefore NumericDate `json:"nbf,omitempty"`
+	IssuedAt  NumericDate `json:"iat,omitempty"`
+	ID        string      `json:"jti,omitempty"`
+}
+
+// Registered 返回注册的声明, 用于校验
+func (c RegisteredClaims) Registered() RegisteredClaims {
+	return c
+}
+
+// Claims JWT声明, 嵌入RegisteredClaims的结构体都实现了此接口
+type Claims interface {
+	Registered() RegisteredClaims
+}
+
+// JWTOptions JWT声明的校验参数
+type JWTOptions struct {
+	// 不为空时, iss必须与之一致
+	Issuer string
+	// 不为空时, aud必须包含此值
+	Audience string
+	// 允许的时钟误差, 用于exp, nbf和iat的校验
+	Leeway time.Duration
+	// 为true时没有exp的token校验失败
+	RequireExpiration bool
+	// 当前时间, 默认为time.Now
+	Now func() time.Time
+}
+
+// SignJWT 将声明序列化为JSON并签名, 生成typ为JWT的compact JWS, key的类型见SignJWS
+func SignJWT[T Claims](claims T, alg JWSAlgorithm, key any, kid string) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	return SignJWS(JWSHeader{Algorithm: alg, Type: "JWT", KeyID: kid}, payload, key)
+}
+
+// ParseJWT 校验JWT的签名和声明, 返回解析后的声明, opts为nil时只校验exp, nbf和iat
+//
+//	claims, err := ParseJWT[UserClaims](token, ES256, pub, &JWTOptions{Issuer: "auth", Leeway: time.Minute})
+//
+// 签名错误时返回ErrVerification, 过期时返回ErrTokenExpired, 其他声明校验失败时返回ErrInvalidClaims
+func ParseJWT[T Claims](token string, alg JWSAlgorithm, key any, opts *JWTOptions) (T, error) {
+	var claims T
+	_, payload, err := VerifyJWS(token, alg, key)
+	if err != nil {
+		return claims, err
+	}
+	// 声明必须是JSON对象, null会使指针类型的T为nil
+	if p := bytes.TrimLeft(payload, " \t\r\n"); len(p) == 0 || p[0] != '{' {
+		return claims, fmt.Errorf("%w: jwt claims must be a json object", ErrInvalidFormat)
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return claims, fmt.Errorf("%w: %s", ErrInvalidFormat, err.Error())
+	}
+	if opts == nil {
+		opts = &JWTOptions{}
+	}
+	return claims, opts.validate(claims.Registered())
+}
+
+// validate 校验注册的声明
+func (o *JWTOptions) validate(c RegisteredClaims) error {
+	now := time.Now()
+	if o.Now != nil {
+		now = o.Now()
+	}
+
+	if c.ExpiresAt == 0 {
+		if o.RequireExpiration {
+			return fmt.Errorf("%w: exp is required", ErrInvalidClaims)
+		}
+	} else if !now.Before(c.ExpiresAt.Time().Add(o.Leeway)) {
+		return fmt.Errorf("%w: expired at %s", ErrTokenExpired, c.ExpiresAt.Time().UTC().Format(time.RFC3339))
+	}
+	if c.NotBefore != 0 && now.Before(c.NotBefore.Time().Add(-o.Leeway)) {
+		return fmt.Errorf("%w: not valid before %s", ErrInvalidClaims, c.NotBefore.Time().UTC().Format(time.RFC3339))
+	}
+	if c.IssuedAt != 0 && now.Before(c.IssuedAt.Time().Add(-o.Leeway)) {
+		return fmt.Errorf("%w: issued in the future", ErrInvalidClaims)
+	}
+	if o.Issuer != "" && c.Issuer != o.Issuer {
+		return fmt.Errorf("%w: unexpected issuer %s", ErrInvalidClaims, c.Issuer)
+	}
+	if o.Audience != "" && !c.Audience.contains(o.Audience) {
+		return fmt.Errorf("%w: audience %s not found", ErrInvalidClaims, o.Audience)
+	}
+	return nil
+}
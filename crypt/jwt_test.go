@@ -0,0 +1,176 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testClaims struct {
+	RegisteredClaims
+	UserID int64  `json:"uid"`
+	Role   string `json:"role,omitempty"`
+}
+
+func fixedNow(unix int64) func() time.Time {
+	return func() time.Time { return time.Unix(unix, 0) }
+}
+
+func TestJWSVector(t *testing.T) {
+	// rfc 7515 appendix A.1
+	key, _ := base64.RawURLEncoding.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	header, payload, err := VerifyJWS(token, HS256, key)
+	assert.Nil(t, err)
+	assert.Equal(t, "JWT", header.Type)
+	assert.True(t, bytes.Contains(payload, []byte(`"iss":"joe"`)))
+
+	claims, err := ParseJWT[RegisteredClaims](token, HS256, key, &JWTOptions{Issuer: "joe", Now: fixedNow(1300819379)})
+	assert.Nil(t, err)
+	assert.Equal(t, NumericDate(1300819380), claims.ExpiresAt)
+	_, err = ParseJWT[RegisteredClaims](token, HS256, key, nil)
+	assert.True(t, errors.Is(err, ErrTokenExpired))
+
+	// signed by openssl dgst -sha256 -sign, converted to r|s
+	token = "eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9" +
+		".eyJpc3MiOiJ6ayIsInN1YiI6IjEyMzQ1Njc4OTAiLCJhdWQiOlsiYXBpIiwid2ViIl0sImlhdCI6MTUxNjIzOTAyMiwiZXhwIjoxNTE2MjQyNjIyfQ" +
+		".uOsdFtUOaiWWNQiZsKe18o84A7DvsQ4vzFgUimNvsXlpxCV2fqrVwNXj9ufvUfbP1WNERRVpQaBkh0Mn9wa7hA"
+	pub, _ := ParseECPublicKey([]byte(testECPublicKey))
+	claims, err = ParseJWT[RegisteredClaims](token, ES256, pub, &JWTOptions{Audience: "web", Now: fixedNow(1516240000)})
+	assert.Nil(t, err)
+	assert.Equal(t, Audience{"api", "web"}, claims.Audience)
+	assert.Equal(t, "1234567890", claims.Subject)
+}
+
+func TestJWTAlgorithms(t *testing.T) {
+	ecKey, _ := ParseECPrivateKey([]byte(testECKey))
+	edKey, _ := GenerateEd25519Key()
+	sm2Key, _ := GenerateSM2Key()
+	secret := bytes.Repeat([]byte("k"), 64)
+
+	for _, c := range []struct {
+		alg  JWSAlgorithm
+		priv any
+		pub  any
+	}{
+		{HS256, secret, secret},
+		{HS384, secret, secret},
+		{HS512, secret, secret},
+		{RS256, testRSAKey, &testRSAKey.PublicKey},
+		{RS512, testRSAKey, &testRSAKey.PublicKey},
+		{PS256, testRSAKey, &testRSAKey.PublicKey},
+		{ES256, ecKey, &ecKey.PublicKey},
+		{EdDSA, edKey, edKey.Public()},
+		{SM2SM3, sm2Key, &sm2Key.PublicKey},
+	} {
+		claims := testClaims{
+			RegisteredClaims: RegisteredClaims{Issuer: "zk", Audience: Audience{"api"}, ExpiresAt: NewNumericDate(time.Now().Add(time.Hour))},
+			UserID:           10086,
+		}
+		token, err := SignJWT(claims, c.alg, c.priv, "key-1")
+		if !assert.Nil(t, err, "%s", c.alg) {
+			continue
+		}
+		header, err := ParseJWSHeader(token)
+		assert.Nil(t, err, "%s", c.alg)
+		assert.Equal(t, "key-1", header.KeyID, "%s", c.alg)
+
+		parsed, err := ParseJWT[testClaims](token, c.alg, c.pub, &JWTOptions{Issuer: "zk", Audience: "api"})
+		assert.Nil(t, err, "%s", c.alg)
+		assert.Equal(t, int64(10086), parsed.UserID, "%s", c.alg)
+		pointer, err := ParseJWT[*testClaims](token, c.alg, c.pub, nil)
+		assert.Nil(t, err, "%s", c.alg)
+		assert.Equal(t, int64(10086), pointer.UserID, "%s", c.alg)
+
+		// tampered payload
+		parts := strings.Split(token, ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"zk","uid":1}`))
+		_, err = ParseJWT[testClaims](strings.Join(parts, "."), c.alg, c.pub, nil)
+		assert.True(t, errors.Is(err, ErrVerification), "%s", c.alg)
+	}
+}
+
+func TestJWTClaims(t *testing.T) {
+	secret := bytes.Repeat([]byte("k"), 32)
+	now := int64(1700000000)
+	sign := func(c RegisteredClaims) string {
+		token, err := SignJWT(c, HS256, secret, "")
+		assert.Nil(t, err)
+		return token
+	}
+	parse := func(token string, opts JWTOptions) error {
+		opts.Now = fixedNow(now)
+		_, err := ParseJWT[RegisteredClaims](token, HS256, secret, &opts)
+		return err
+	}
+
+	expired := sign(RegisteredClaims{ExpiresAt: NumericDate(now - 30)})
+	assert.True(t, errors.Is(parse(expired, JWTOptions{}), ErrTokenExpired))
+	assert.Nil(t, parse(expired, JWTOptions{Leeway: time.Minute}))
+
+	notBefore := sign(RegisteredClaims{NotBefore: NumericDate(now + 30)})
+	assert.True(t, errors.Is(parse(notBefore, JWTOptions{}), ErrInvalidClaims))
+	assert.Nil(t, parse(notBefore, JWTOptions{Leeway: time.Minute}))
+
+	future := sign(RegisteredClaims{IssuedAt: NumericDate(now + 30)})
+	assert.True(t, errors.Is(parse(future, JWTOptions{}), ErrInvalidClaims))
+	assert.Nil(t, parse(future, JWTOptions{Leeway: time.Minute}))
+
+	token := sign(RegisteredClaims{Issuer: "zk", Audience: Audience{"api"}})
+	assert.Nil(t, parse(token, JWTOptions{Issuer: "zk", Audience: "api"}))
+	assert.True(t, errors.Is(parse(token, JWTOptions{Issuer: "other"}), ErrInvalidClaims))
+	assert.True(t, errors.Is(parse(token, JWTOptions{Audience: "web"}), ErrInvalidClaims))
+	assert.True(t, errors.Is(parse(token, JWTOptions{RequireExpiration: true}), ErrInvalidClaims))
+
+	// single audience is a string, fractional dates are accepted
+	_, payload, _ := VerifyJWS(token, HS256, secret)
+	assert.Equal(t, `{"iss":"zk","aud":"api"}`, string(payload))
+	fractional, _ := SignJWS(JWSHeader{Algorithm: HS256}, []byte(`{"exp":1700000000.5}`), secret)
+	assert.True(t, errors.Is(parse(fractional, JWTOptions{}), ErrTokenExpired))
+
+	// claims must be an object
+	for _, payload := range []string{"null", " null", "[]", "1", `"x"`, ""} {
+		token, _ := SignJWS(JWSHeader{Algorithm: HS256}, []byte(payload), secret)
+		_, err := ParseJWT[*RegisteredClaims](token, HS256, secret, nil)
+		assert.True(t, errors.Is(err, ErrInvalidFormat), "%s", payload)
+	}
+}
+
+func TestJWSErrors(t *testing.T) {
+	secret := bytes.Repeat([]byte("k"), 32)
+	token, _ := SignJWS(JWSHeader{Algorithm: HS256}, []byte("{}"), secret)
+
+	// algorithm confusion and none
+	_, _, err := VerifyJWS(token, RS256, &testRSAKey.PublicKey)
+	assert.True(t, errors.Is(err, ErrVerification))
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + ".e30."
+	_, _, err = VerifyJWS(none, HS256, secret)
+	assert.True(t, errors.Is(err, ErrVerification))
+	crit, _ := SignJWS(JWSHeader{Algorithm: HS256, Critical: []string{"exp"}}, []byte("{}"), secret)
+	_, _, err = VerifyJWS(crit, HS256, secret)
+	assert.True(t, errors.Is(err, ErrVerification))
+
+	_, err = SignJWS(JWSHeader{Algorithm: HS256}, []byte("{}"), secret[:16])
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = SignJWS(JWSHeader{Algorithm: HS256}, []byte("{}"), "secret")
+	var typeErr *KeyTypeError
+	assert.True(t, errors.As(err, &typeErr))
+	_, err = SignJWS(JWSHeader{Algorithm: "none"}, []byte("{}"), secret)
+	assert.True(t, errors.Is(err, ErrInvalidAlgorithm))
+	ecKey, _ := GenerateECDSAKey(elliptic.P384())
+	_, err = SignJWS(JWSHeader{Algorithm: ES256}, []byte("{}"), ecKey)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	for _, bad := range []string{"", "a.b", "a.b.c.d", "!.e30.", "e30.e30.!"} {
+		_, _, err = VerifyJWS(bad, HS256, secret)
+		assert.True(t, errors.Is(err, ErrInvalidFormat), "%s", bad)
+	}
+}
@@ -0,0 +1,119 @@
+package crypt
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PBKDF2 使用PBKDF2从密码派生密钥, 结果可直接作为AESEncrypt/SM4Encrypt的key
+// 参数:
+//   - password: 密码
+//   - salt: 盐, 建议至少16字节随机数
+//   - iter: 迭代次数, 不能小于1
+//   - keyLen: 派生密钥的长度
+//   - h: hmac使用的hash算法, 如SHA256, SM3
+//
+// 返回:
+//   - 派生的密钥
+//   - 错误信息(如果有)
+func PBKDF2(password, salt []byte, iter, keyLen int, h Hash) ([]byte, error) {
+	newHash, err := h.macHash()
+	if err != nil {
+		return nil, err
+	}
+	if iter < 1 {
+		return nil, fmt.Errorf("%w: pbkdf2 iterations %d", ErrInvalidFormat, iter)
+	}
+	if keyLen < 1 {
+		return nil, fmt.Errorf("%w: pbkdf2 key length %d", ErrInvalidKey, keyLen)
+	}
+	return pbkdf2.Key(password, salt, iter, keyLen, newHash), nil
+}
+
+// HKDFExtract HKDF(RFC 5869)的extract步骤, 从共享密钥中提取伪随机密钥(PRK)
+// salt为空时使用hash长度的全0
+func HKDFExtract(h Hash, secret, salt []byte) ([]byte, error) {
+	newHash, err := h.macHash()
+	if err != nil {
+		return nil, err
+	}
+	return hkdf.Extract(newHash, secret, salt), nil
+}
+
+// HKDFExpand HKDF(RFC 5869)的expand步骤, 使用PRK和info派生keyLen长度的密钥
+// keyLen不能超过hash长度的255倍
+func HKDFExpand(h Hash, prk, info []byte, keyLen int) ([]byte, error) {
+	newHash, err := h.macHash()
+	if err != nil {
+		return nil, err
+	}
+	return hkdfRead(hkdf.Expand(newHash, prk, info), keyLen)
+}
+
+// HKDF 依次执行extract和expand, 从共享密钥派生密钥
+// info用于区分用途, 同一个secret派生不同用途的密钥时info必须不同
+//
+//	key, err := HKDF(SHA256, secret, salt, []byte("order-aes-key"), 32)
+func HKDF(h Hash, secret, salt, info []byte, keyLen int) ([]byte, error) {
+	newHash, err := h.macHash()
+	if err != nil {
+		return nil, err
+	}
+	return hkdfRead(hkdf.New(newHash, secret, salt, info), keyLen)
+}
+
+// hkdfRead 从HKDF读取指定长度的密钥
+func hkdfRead(r io.Reader, keyLen int) ([]byte, error) {
+	if keyLen < 1 {
+		return nil, fmt.Errorf("%w: hkdf key length %d", ErrInvalidKey, keyLen)
+	}
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, fmt.Errorf("%w: hkdf key length %d too large: %v", ErrInvalidKey, keyLen, err)
+	}
+	return key, nil
+}
+
+// EVPBytesToKey OpenSSL的EVP_BytesToKey, 迭代次数为1, 与openssl enc不带-pbkdf2时的派生方式一致
+// 仅用于兼容openssl enc等旧格式, 新代码应使用PBKDF2或HKDF
+// 参数:
+//   - password: 密码
+//   - salt: 8字节盐, 为空时不加盐(openssl enc -nosalt)
+//   - keyLen: 密钥长度, 不能小于1
+//   - ivLen: iv长度, 不需要iv时为0
+//   - h: hash算法, openssl 1.1.0之前默认为MD5, 之后默认为SHA256
+//
+// 返回:
+//   - 密钥和iv
+//   - 错误信息(如果有)
+func EVPBytesToKey(password, salt []byte, keyLen, ivLen int, h Hash) ([]byte, []byte, error) {
+	newHash, err := h.macHash()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(salt) != 0 && len(salt) != 8 {
+		return nil, nil, fmt.Errorf("%w: evp salt length must be 8, got %d", ErrInvalidFormat, len(salt))
+	}
+	if keyLen < 1 {
+		return nil, nil, fmt.Errorf("%w: evp key length %d", ErrInvalidKey, keyLen)
+	}
+	if ivLen < 0 {
+		return nil, nil, fmt.Errorf("%w: evp iv length %d", ErrInvalidIV, ivLen)
+	}
+
+	d := newHash()
+	var out, prev []byte
+	for len(out) < keyLen+ivLen {
+		// D_i = HASH(D_(i-1) || password || salt)
+		d.Reset()
+		d.Write(prev)
+		d.Write(password)
+		d.Write(salt)
+		prev = d.Sum(nil)
+		out = append(out, prev...)
+	}
+	return out[:keyLen], out[keyLen : keyLen+ivLen], nil
+}
@@ -0,0 +1,93 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// rfc 7914 section 11
+	key, err := PBKDF2([]byte("passwd"), []byte("salt"), 1, 64, SHA256)
+	assert.Nil(t, err)
+	assert.Equal(t, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783", hex.EncodeToString(key))
+
+	// openssl kdf -kdfopt digest:SM3 PBKDF2
+	key, err = PBKDF2([]byte("zk-secret"), []byte("zk-salt"), 10000, 16, SM3)
+	assert.Nil(t, err)
+	assert.Equal(t, "d0cbc96bff48914301758dc9d986b275", hex.EncodeToString(key))
+
+	// derived key plugs into the block cipher functions
+	plain := []byte("derived from passphrase")
+	encrypted, err := SM4Encrypt(plain, key, key, CBC, PKCS7)
+	assert.Nil(t, err)
+	decrypted, err := SM4Decrypt(encrypted, key, key, CBC, PKCS7)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	_, err = PBKDF2([]byte("passwd"), []byte("salt"), 0, 32, SHA256)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	_, err = PBKDF2([]byte("passwd"), []byte("salt"), 1, 0, SHA256)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = PBKDF2([]byte("passwd"), []byte("salt"), 1, 32, CRC32)
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+}
+
+func TestHKDF(t *testing.T) {
+	// rfc 5869 test case 1
+	secret, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	expect := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"
+
+	prk, err := HKDFExtract(SHA256, secret, salt)
+	assert.Nil(t, err)
+	assert.Equal(t, "077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5", hex.EncodeToString(prk))
+	key, err := HKDFExpand(SHA256, prk, info, 42)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, hex.EncodeToString(key))
+	key, err = HKDF(SHA256, secret, salt, info, 42)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, hex.EncodeToString(key))
+
+	// openssl kdf -kdfopt digest:SM3 HKDF
+	key, err = HKDF(SM3, []byte("shared-secret"), []byte("zk"), []byte("sm4-key"), 16)
+	assert.Nil(t, err)
+	assert.Equal(t, "40fcc304e3abb3a01763a0a92bd6f824", hex.EncodeToString(key))
+
+	_, err = HKDFExpand(SHA256, prk, info, 255*32+1)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = HKDF(SHA256, secret, salt, info, 0)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestEVPBytesToKey(t *testing.T) {
+	// openssl enc -aes-256-cbc -P -md md5 -S 0102030405060708 -pass pass:secret
+	salt, _ := hex.DecodeString("0102030405060708")
+	key, iv, err := EVPBytesToKey([]byte("secret"), salt, 32, 16, MD5)
+	assert.Nil(t, err)
+	assert.Equal(t, "C9E5A1BD216DBE1317E230CEF48F38EE7F0E17AD64022144BCCEC4A1AA2879AB", strings.ToUpper(hex.EncodeToString(key)))
+	assert.Equal(t, "E24B32BBBC4EF02ECBCB6576523AD893", strings.ToUpper(hex.EncodeToString(iv)))
+
+	// openssl enc -aes-128-cbc -P -md sha256 -nosalt -pass pass:secret
+	key, iv, err = EVPBytesToKey([]byte("secret"), nil, 16, 16, SHA256)
+	assert.Nil(t, err)
+	assert.Equal(t, "2BB80D537B1DA3E38BD30361AA855686", strings.ToUpper(hex.EncodeToString(key)))
+	assert.Equal(t, "BDE0EACD7162FEF6A25FE97BF527A25B", strings.ToUpper(hex.EncodeToString(iv)))
+
+	_, _, err = EVPBytesToKey([]byte("secret"), salt[:4], 16, 16, SHA256)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	_, _, err = EVPBytesToKey([]byte("secret"), salt, -1, 16, SHA256)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, _, err = EVPBytesToKey([]byte("secret"), salt, 16, -1, SHA256)
+	assert.True(t, errors.Is(err, ErrInvalidIV))
+
+	// no iv, e.g. for ECB
+	key, iv, err = EVPBytesToKey([]byte("secret"), salt, 16, 0, SHA256)
+	assert.Nil(t, err)
+	assert.Equal(t, 16, len(key))
+	assert.Equal(t, 0, len(iv))
+}
@@ -0,0 +1,93 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+)
+
+// key output format
+type KeyFormat string
+
+const (
+	//pem with header and footer
+	PEM KeyFormat = "PEM"
+	//raw der bytes
+	DER KeyFormat = "DER"
+	//single line base64 of der without header, as ParsePublicKey and ParsePemLine accept
+	PEMLINE KeyFormat = "PEMLINE"
+)
+
+// encodeKey 将DER编码的密钥转为指定格式
+func encodeKey(der []byte, pemType string, format KeyFormat) ([]byte, error) {
+	switch format {
+	case PEM:
+		return pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der}), nil
+	case DER:
+		return der, nil
+	case PEMLINE:
+		out := make([]byte, base64.StdEncoding.EncodedLen(len(der)))
+		base64.StdEncoding.Encode(out, der)
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyFormat, format)
+	}
+}
+
+// decodeKey 读取PEM或单行base64格式的密钥, 返回DER编码的数据
+func decodeKey(in []byte) ([]byte, error) {
+	in = bytes.TrimSpace(in)
+	if bytes.HasPrefix(in, pemStart) {
+		block, _ := pem.Decode(in)
+		if block == nil {
+			return nil, ErrInvalidKeyFormat
+		}
+		return block.Bytes, nil
+	}
+	der, err := base64.StdEncoding.DecodeString(string(in))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyFormat, err.Error())
+	}
+	return der, nil
+}
+
+// marshal private key to PKCS#8, type "PRIVATE KEY" in pem format
+func MarshalPKCS8PrivateKey(key crypto.PrivateKey, format KeyFormat) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return encodeKey(der, "PRIVATE KEY", format)
+}
+
+// marshal public key to PKIX (SubjectPublicKeyInfo), type "PUBLIC KEY" in pem format
+func MarshalPKIXPublicKey(key crypto.PublicKey, format KeyFormat) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return encodeKey(der, "PUBLIC KEY", format)
+}
+
+// 读取PKIX公钥, 返回*rsa.PublicKey, *ecdsa.PublicKey或ed25519.PublicKey
+// 公钥可以没有如 -----BEGIN PUBLIC KEY-----的前缀后缀
+func ParsePKIXPublicKey(in []byte) (crypto.PublicKey, error) {
+	der, err := decodeKey(in)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKIXPublicKey(der)
+}
+
+// 读取PKCS#8私钥, 返回*rsa.PrivateKey, *ecdsa.PrivateKey或ed25519.PrivateKey
+// 私钥可以是PEM, 也可以是没有前缀后缀的单行base64
+func ParsePKCS8PrivateKey(in []byte) (crypto.PrivateKey, error) {
+	der, err := decodeKey(in)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKCS8PrivateKey(der)
+}
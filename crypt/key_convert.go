@@ -0,0 +1,195 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// key structure (ASN.1 syntax) of the der data
+type KeyStructure string
+
+const (
+	//rsa only, private key "RSA PRIVATE KEY" or public key "RSA PUBLIC KEY", as php openssl and old openssl output
+	PKCS1 KeyStructure = "PKCS1"
+	//private key of any type, "PRIVATE KEY", as java PKCS8EncodedKeySpec
+	PKCS8 KeyStructure = "PKCS8"
+	//SubjectPublicKeyInfo, "PUBLIC KEY", as java X509EncodedKeySpec
+	PKIX KeyStructure = "PKIX"
+	//ecdsa private key "EC PRIVATE KEY", as openssl ecparam -genkey output
+	SEC1 KeyStructure = "SEC1"
+)
+
+// KeyInfo DetectKey识别出的密钥信息
+type KeyInfo struct {
+	// 输入的格式: PEM, DER或PEMLINE(单行或多行的base64)
+	Format KeyFormat
+	// 密钥的结构, 根据数据识别, 与PEM头部的类型无关
+	Structure KeyStructure
+	// 是否为私钥
+	Private bool
+	// 密钥: *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey, *sm2.PrivateKey, *ecdh.PrivateKey(X25519)或对应的公钥
+	Key any
+}
+
+// DetectKey 自动识别密钥的格式和结构并读取密钥
+// 输入可以是PEM, DER或没有前缀后缀的base64(可以包含换行), 结构可以是PKCS#1, PKCS#8, PKIX或SEC1
+// PEM头部的类型可能与数据不符(如PKIX公钥使用了"RSA PUBLIC KEY"), 只根据数据识别结构
+// 加密的私钥返回ErrInvalidKeyFormat, 需要使用ParsePrivateKeyWithPassword
+func DetectKey(in []byte) (*KeyInfo, error) {
+	info := &KeyInfo{}
+	var der []byte
+	trimmed := bytes.TrimSpace(in)
+	if bytes.HasPrefix(trimmed, pemStart) {
+		block, _ := pem.Decode(trimmed)
+		if block == nil {
+			return nil, fmt.Errorf("%w: broken pem", ErrInvalidKeyFormat)
+		}
+		if block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block) {
+			return nil, fmt.Errorf("%w: encrypted private key, use ParsePrivateKeyWithPassword", ErrInvalidKeyFormat)
+		}
+		info.Format, der = PEM, block.Bytes
+	} else if b, err := base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(trimmed), nil))); err == nil {
+		info.Format, der = PEMLINE, b
+	} else {
+		info.Format, der = DER, in
+	}
+
+	var err error
+	if info.Structure, info.Private, info.Key, err = parseAnyKey(der); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ConvertKey 将任意格式和结构的密钥转换为指定的结构和格式
+// 私钥转换为PKIX时输出对应的公钥; 转换为PKCS#8和SEC1时必须是私钥
+//
+//	// PHP生成的PKCS#1公钥转换为java使用的单行PKIX公钥
+//	out, err := ConvertKey(phpKey, PKIX, PEMLINE)
+func ConvertKey(in []byte, structure KeyStructure, format KeyFormat) ([]byte, error) {
+	info, err := DetectKey(in)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalKey(info.Key, structure, format)
+}
+
+// MarshalKey 将密钥编码为指定的结构和格式, key为私钥或公钥
+//   - PKCS1: *rsa.PrivateKey或*rsa.PublicKey
+//   - PKCS8: 任意类型的私钥, 包括X25519的*ecdh.PrivateKey
+//   - PKIX: 任意类型的公钥, 私钥时输出其公钥
+//   - SEC1: *ecdsa.PrivateKey或*sm2.PrivateKey
+func MarshalKey(key any, structure KeyStructure, format KeyFormat) ([]byte, error) {
+	switch structure {
+	case PKCS1:
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return MarshalPKCS1PrivateKey(k, format)
+		case *rsa.PublicKey:
+			return encodeKey(x509.MarshalPKCS1PublicKey(k), "RSA PUBLIC KEY", format)
+		default:
+			return nil, keyTypeError("*rsa.PrivateKey or *rsa.PublicKey", key)
+		}
+	case PKCS8:
+		if _, ok := key.(privateKey); !ok {
+			return nil, keyTypeError("private key", key)
+		}
+		der, err := marshalPKCS8(key)
+		if err != nil {
+			return nil, err
+		}
+		return encodeKey(der, "PRIVATE KEY", format)
+	case PKIX:
+		pub := publicKeyOf(key)
+		if k, ok := pub.(*sm2.PublicKey); ok {
+			return MarshalSM2PublicKey(k, format)
+		}
+		return MarshalPKIXPublicKey(pub, format)
+	case SEC1:
+		switch k := key.(type) {
+		case *ecdsa.PrivateKey:
+			return MarshalECPrivateKey(k, format)
+		case *sm2.PrivateKey:
+			der, err := marshalSM2SEC1(k)
+			if err != nil {
+				return nil, err
+			}
+			return encodeKey(der, "EC PRIVATE KEY", format)
+		default:
+			return nil, keyTypeError("*ecdsa.PrivateKey or *sm2.PrivateKey", key)
+		}
+	default:
+		return nil, fmt.Errorf("%w: key structure %s", ErrInvalidKeyFormat, structure)
+	}
+}
+
+// parseAnyKey 依次尝试各种结构读取DER编码的密钥
+func parseAnyKey(der []byte) (KeyStructure, bool, any, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return PKCS1, true, key, nil
+	}
+	if key, err := parsePKCS8(der); err == nil {
+		return PKCS8, true, key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return PKCS1, false, key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return SEC1, true, key, nil
+	}
+	if key, err := parseSM2SEC1(der, false); err == nil {
+		return SEC1, true, key, nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return PKIX, false, key, nil
+	}
+	if key, err := parseSM2PKIX(der); err == nil {
+		return PKIX, false, key, nil
+	}
+	return "", false, nil, fmt.Errorf("%w: unknown key structure", ErrInvalidKeyFormat)
+}
+
+// privateKey 所有支持的私钥类型都实现了Public, 包括不能签名的*ecdh.PrivateKey
+type privateKey interface {
+	Public() crypto.PublicKey
+}
+
+// publicKeyOf 私钥返回对应的公钥, 其他原样返回
+func publicKeyOf(key any) any {
+	if k, ok := key.(privateKey); ok {
+		return k.Public()
+	}
+	return key
+}
+
+// pemLineType 根据DER数据选择单行密钥的PEM类型, 无法识别时按private选择PKCS#8或PKIX
+func pemLineType(der []byte, private bool) string {
+	structure, isPrivate, _, err := parseAnyKey(der)
+	if err != nil {
+		structure, isPrivate = PKIX, private
+		if private {
+			structure = PKCS8
+		}
+	}
+	switch structure {
+	case PKCS1:
+		if isPrivate {
+			return "RSA PRIVATE KEY"
+		}
+		return "RSA PUBLIC KEY"
+	case SEC1:
+		return "EC PRIVATE KEY"
+	case PKCS8:
+		return "PRIVATE KEY"
+	default:
+		return "PUBLIC KEY"
+	}
+}
@@ -0,0 +1,306 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tjfoc/gmsm/sm2"
+	gmx509 "github.com/tjfoc/gmsm/x509"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// 加密PKCS#8私钥使用的参数: PBES2, PBKDF2-HMAC-SHA256, AES-256-CBC
+const (
+	pkcs8SaltSize   = 16
+	pkcs8Iterations = 100000
+	//读取时允许的最大迭代次数, 防止恶意的迭代次数导致长时间计算
+	pkcs8MaxIterations = 10000000
+)
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidSM4CBC         = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 104, 2}
+)
+
+// encryptedPrivateKeyInfo RFC 5958
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params RFC 8018
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params RFC 8018, prf默认为hmacWithSHA1
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// pbes2Cipher 根据加密算法的oid返回算法和密钥长度
+func pbes2Cipher(oid asn1.ObjectIdentifier) (Algorithm, int, error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return AES, 16, nil
+	case oid.Equal(oidAES192CBC):
+		return AES, 24, nil
+	case oid.Equal(oidAES256CBC):
+		return AES, 32, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return DES3, 24, nil
+	case oid.Equal(oidSM4CBC):
+		return SM4, 16, nil
+	default:
+		return "", 0, fmt.Errorf("%w: pbes2 cipher %s", ErrInvalidAlgorithm, oid)
+	}
+}
+
+// pbkdf2Hash 根据prf的oid返回hash算法
+func pbkdf2Hash(oid asn1.ObjectIdentifier) (Hash, error) {
+	switch {
+	case len(oid) == 0 || oid.Equal(oidHMACWithSHA1):
+		return SHA1, nil
+	case oid.Equal(oidHMACWithSHA256):
+		return SHA256, nil
+	case oid.Equal(oidHMACWithSHA384):
+		return SHA384, nil
+	case oid.Equal(oidHMACWithSHA512):
+		return SHA512, nil
+	default:
+		return "", fmt.Errorf("%w: pbkdf2 prf %s", ErrInvalidHash, oid)
+	}
+}
+
+// marshalPKCS8 将私钥编码为PKCS#8, 支持rsa, ecdsa, ed25519和sm2私钥
+func marshalPKCS8(key crypto.PrivateKey) ([]byte, error) {
+	if sm2Key, ok := key.(*sm2.PrivateKey); ok {
+		return gmx509.MarshalSm2UnecryptedPrivateKey(sm2Key)
+	}
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// parsePKCS8 读取PKCS#8私钥, 支持rsa, ecdsa, ed25519和sm2私钥
+func parsePKCS8(der []byte) (crypto.PrivateKey, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err == nil {
+		return key, nil
+	}
+	if sm2Key, err2 := parseSM2PKCS8(der); err2 == nil {
+		return sm2Key, nil
+	}
+	return nil, err
+}
+
+// marshal private key to password encrypted PKCS#8, type "ENCRYPTED PRIVATE KEY" in pem format
+// the key is encrypted with PBES2, PBKDF2-HMAC-SHA256 and AES-256-CBC, as openssl pkcs8 -topk8 -v2 aes-256-cbc
+func MarshalEncryptedPKCS8PrivateKey(key crypto.PrivateKey, password []byte, format KeyFormat) ([]byte, error) {
+	der, err := marshalPKCS8(key)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, pkcs8SaltSize)
+	iv := make([]byte, 16)
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	encrypted, err := AESEncrypt(der, pbkdf2.Key(password, salt, pkcs8Iterations, 32, sha256.New), iv, CBC, PKCS7)
+	if err != nil {
+		return nil, err
+	}
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pkcs8Iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	out, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return encodeKey(out, "ENCRYPTED PRIVATE KEY", format)
+}
+
+// decryptPKCS8 解密PKCS#8私钥, 返回未加密的PKCS#8数据
+func decryptPKCS8(der, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyFormat, err.Error())
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("%w: only PBES2 is supported, got %s", ErrInvalidAlgorithm, info.Algorithm.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyFormat, err.Error())
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("%w: only PBKDF2 is supported, got %s", ErrInvalidAlgorithm, params.KeyDerivationFunc.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyFormat, err.Error())
+	}
+	h, err := pbkdf2Hash(kdf.PRF.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	alg, keySize, err := pbes2Cipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if kdf.IterationCount <= 0 || kdf.IterationCount > pkcs8MaxIterations {
+		return nil, fmt.Errorf("%w: pbkdf2 iteration count %d", ErrInvalidKeyFormat, kdf.IterationCount)
+	}
+	if kdf.KeyLength != 0 && kdf.KeyLength != keySize {
+		return nil, fmt.Errorf("%w: pbkdf2 key length %d", ErrInvalidKey, kdf.KeyLength)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyFormat, err.Error())
+	}
+
+	newHash, _ := h.newHash()
+	c, err := NewCipher(alg, pbkdf2.Key(password, kdf.Salt, kdf.IterationCount, keySize, newHash), WithIV(iv))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := c.Decrypt(info.EncryptedData)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+	return plain, nil
+}
+
+// marshal private key to legacy encrypted pem with "Proc-Type: 4,ENCRYPTED" header, encrypted with AES-256-CBC
+// rsa key is marshalled to PKCS#1 "RSA PRIVATE KEY", ecdsa key to SEC1 "EC PRIVATE KEY", others to PKCS#8 "PRIVATE KEY"
+// legacy pem uses a weak md5 based key derivation, only for systems which do not support encrypted PKCS#8
+func MarshalEncryptedPEMPrivateKey(key crypto.PrivateKey, password []byte) ([]byte, error) {
+	var der []byte
+	var pemType string
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		der, pemType = x509.MarshalPKCS1PrivateKey(k), "RSA PRIVATE KEY"
+	case *ecdsa.PrivateKey:
+		der, err = x509.MarshalECPrivateKey(k)
+		pemType = "EC PRIVATE KEY"
+	default:
+		der, err = marshalPKCS8(key)
+		pemType = "PRIVATE KEY"
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, err := x509.EncryptPEMBlock(rand.Reader, pemType, der, password, x509.PEMCipherAES256)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// 读取私钥, 支持加密的PKCS#8("ENCRYPTED PRIVATE KEY")和带"Proc-Type: 4,ENCRYPTED"头的旧格式加密PEM
+// 未加密的PKCS#1, SEC1和PKCS#8私钥直接读取, 不使用密码
+// 返回*rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey或*sm2.PrivateKey, 密码错误时返回ErrInvalidPassword
+func ParsePrivateKeyWithPassword(in, password []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(in)
+	if block == nil {
+		// 单行base64或DER, 只支持PKCS#8
+		der, err := decodeKey(in)
+		if err != nil {
+			der = in
+		}
+		return parseKeyDER(der, password, "")
+	}
+
+	der := block.Bytes
+	if x509.IsEncryptedPEMBlock(block) {
+		var err error
+		if der, err = x509.DecryptPEMBlock(block, password); err != nil {
+			if errors.Is(err, x509.IncorrectPasswordError) {
+				return nil, ErrInvalidPassword
+			}
+			return nil, err
+		}
+		key, err := parseKeyDER(der, nil, block.Type)
+		if err != nil {
+			// 密码错误时也可能通过填充校验
+			return nil, ErrInvalidPassword
+		}
+		return key, nil
+	}
+	return parseKeyDER(der, password, block.Type)
+}
+
+// parseKeyDER 按PEM类型读取DER编码的私钥, 类型为空时自动识别
+func parseKeyDER(der, password []byte, pemType string) (crypto.PrivateKey, error) {
+	switch pemType {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		if key, err := x509.ParseECPrivateKey(der); err == nil {
+			return key, nil
+		}
+		return parseSM2SEC1(der, false)
+	case "PRIVATE KEY":
+		return parsePKCS8(der)
+	}
+
+	if key, err := parsePKCS8(der); err == nil {
+		return key, nil
+	}
+	plain, err := decryptPKCS8(der, password)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePKCS8(plain)
+	if err != nil {
+		// 密码错误时也可能通过填充校验
+		return nil, ErrInvalidPassword
+	}
+	return key, nil
+}
@@ -0,0 +1,193 @@
+package crypt
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 密钥环密文格式版本
+const keyRingVersion = 1
+
+// 密钥环密文中的算法
+const (
+	keyRingAESGCM = 1 // AES-GCM
+	keyRingSM4GCM = 2 // SM4-GCM
+)
+
+// ringKey 密钥环中的密钥
+type ringKey struct {
+	header []byte
+	cipher *Cipher
+}
+
+// KeyRing 带密钥ID的密钥环, 用于数据库字段等存储数据的加密和密钥轮换
+// 密文格式: 版本(1) | 算法(1) | ID长度(1) | ID | nonce(12) | 密文 | tag(16), 头部作为GCM的附加数据
+// 新数据使用当前密钥加密, 旧数据根据头部的ID使用已停用的密钥解密, 可并发使用
+//
+//	ring := NewKeyRing()
+//	ring.Add("2023", AES, oldKey)
+//	ring.Add("2024", AES, newKey)
+//	ring.SetActive("2024")
+//	data, changed, err := ring.ReEncrypt(data)
+type KeyRing struct {
+	mu     sync.RWMutex
+	keys   map[string]*ringKey
+	active string
+}
+
+// NewKeyRing 创建空的密钥环, 添加密钥并设置当前密钥后才能加密
+func NewKeyRing() *KeyRing {
+	return &KeyRing{keys: make(map[string]*ringKey)}
+}
+
+// Add 添加密钥, alg为AES或SM4, 使用GCM模式
+// id长度为1~255字节, 同一个id不能重复添加; 密钥环中没有当前密钥时, 第一个添加的密钥成为当前密钥
+func (r *KeyRing) Add(id string, alg Algorithm, key []byte) error {
+	if len(id) == 0 || len(id) > 255 {
+		return fmt.Errorf("%w: key id length must be 1~255, got %d", ErrInvalidKey, len(id))
+	}
+	var code byte
+	switch alg {
+	case AES:
+		code = keyRingAESGCM
+	case SM4:
+		code = keyRingSM4GCM
+	default:
+		return fmt.Errorf("%w: key ring algorithm %s", ErrInvalidAlgorithm, alg)
+	}
+
+	header := append([]byte{keyRingVersion, code, byte(len(id))}, id...)
+	c, err := NewCipher(alg, key, WithMode(GCM), WithAAD(header))
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[id]; ok {
+		return fmt.Errorf("%w: duplicate key id %s", ErrInvalidKey, id)
+	}
+	r.keys[id] = &ringKey{header: header, cipher: c}
+	if r.active == "" {
+		r.active = id
+	}
+	return nil
+}
+
+// SetActive 设置加密新数据使用的密钥
+func (r *KeyRing) SetActive(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	r.active = id
+	return nil
+}
+
+// Active 返回当前密钥的ID
+func (r *KeyRing) Active() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+// Remove 删除已停用的密钥, 所有数据都重新加密后才能删除, 不能删除当前密钥
+func (r *KeyRing) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id == r.active {
+		return fmt.Errorf("%w: can not remove active key %s", ErrInvalidKey, id)
+	}
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+// Encrypt 使用当前密钥加密数据, 密文带有密钥ID和算法
+func (r *KeyRing) Encrypt(plain []byte) ([]byte, error) {
+	r.mu.RLock()
+	key := r.keys[r.active]
+	r.mu.RUnlock()
+	if key == nil {
+		return nil, fmt.Errorf("%w: no active key", ErrKeyNotFound)
+	}
+
+	sealed, err := key.cipher.Encrypt(plain)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(key.header)+len(sealed))
+	return append(append(out, key.header...), sealed...), nil
+}
+
+// Decrypt 根据密文头部的密钥ID选择密钥解密
+// 格式错误时返回ErrInvalidFormat, 密钥不存在时返回ErrKeyNotFound, 数据被篡改时返回ErrAuthentication
+func (r *KeyRing) Decrypt(data []byte) ([]byte, error) {
+	key, header, err := r.lookup(data)
+	if err != nil {
+		return nil, err
+	}
+	return key.cipher.Decrypt(data[len(header):])
+}
+
+// KeyID 返回加密数据使用的密钥ID
+func (r *KeyRing) KeyID(data []byte) (string, error) {
+	id, _, err := parseKeyRingHeader(data)
+	return id, err
+}
+
+// ReEncrypt 将不是当前密钥加密的数据解密后使用当前密钥重新加密
+// 已经是当前密钥加密的数据原样返回, changed为false
+func (r *KeyRing) ReEncrypt(data []byte) (out []byte, changed bool, err error) {
+	id, _, err := parseKeyRingHeader(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if id == r.Active() {
+		return data, false, nil
+	}
+	plain, err := r.Decrypt(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if out, err = r.Encrypt(plain); err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// lookup 读取头部并查找密钥, 头部中的算法必须与密钥一致
+func (r *KeyRing) lookup(data []byte) (*ringKey, []byte, error) {
+	id, header, err := parseKeyRingHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.mu.RLock()
+	key := r.keys[id]
+	r.mu.RUnlock()
+	if key == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	if key.header[1] != header[1] {
+		return nil, nil, fmt.Errorf("%w: algorithm mismatch for key %s", ErrInvalidFormat, id)
+	}
+	return key, header, nil
+}
+
+// parseKeyRingHeader 读取密文头部, 返回密钥ID和头部数据
+func parseKeyRingHeader(data []byte) (string, []byte, error) {
+	if len(data) < 3 {
+		return "", nil, fmt.Errorf("%w: key ring data too short", ErrInvalidFormat)
+	}
+	if data[0] != keyRingVersion {
+		return "", nil, fmt.Errorf("%w: unsupported key ring version %d", ErrInvalidFormat, data[0])
+	}
+	size := 3 + int(data[2])
+	if data[2] == 0 || len(data) < size {
+		return "", nil, fmt.Errorf("%w: malformed key ring header", ErrInvalidFormat)
+	}
+	return string(data[3:size]), data[:size], nil
+}
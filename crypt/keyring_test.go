@@ -0,0 +1,86 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyRing(t *testing.T) {
+	ring := NewKeyRing()
+	_, err := ring.Encrypt([]byte("x"))
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	assert.Nil(t, ring.Add("2023", AES, bytes.Repeat([]byte{1}, 32)))
+	assert.Equal(t, "2023", ring.Active())
+	plain := []byte("13800138000")
+	old, err := ring.Encrypt(plain)
+	assert.Nil(t, err)
+	id, err := ring.KeyID(old)
+	assert.Nil(t, err)
+	assert.Equal(t, "2023", id)
+
+	// rotate
+	assert.Nil(t, ring.Add("2024", SM4, bytes.Repeat([]byte{2}, 16)))
+	assert.Equal(t, "2023", ring.Active())
+	assert.Nil(t, ring.SetActive("2024"))
+	encrypted, err := ring.Encrypt(plain)
+	assert.Nil(t, err)
+	id, _ = ring.KeyID(encrypted)
+	assert.Equal(t, "2024", id)
+
+	for _, data := range [][]byte{old, encrypted} {
+		decrypted, err := ring.Decrypt(data)
+		assert.Nil(t, err)
+		assert.Equal(t, plain, decrypted)
+	}
+
+	upgraded, changed, err := ring.ReEncrypt(old)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	id, _ = ring.KeyID(upgraded)
+	assert.Equal(t, "2024", id)
+	same, changed, err := ring.ReEncrypt(upgraded)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, upgraded, same)
+
+	// retired key removed after migration
+	assert.NotNil(t, ring.Remove("2024"))
+	assert.Nil(t, ring.Remove("2023"))
+	_, err = ring.Decrypt(old)
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+	decrypted, err := ring.Decrypt(upgraded)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+}
+
+func TestKeyRingErrors(t *testing.T) {
+	ring := NewKeyRing()
+	key := bytes.Repeat([]byte{1}, 16)
+	assert.True(t, errors.Is(ring.Add("", AES, key), ErrInvalidKey))
+	assert.True(t, errors.Is(ring.Add("k1", DES, key[:8]), ErrInvalidAlgorithm))
+	assert.True(t, errors.Is(ring.Add("k1", AES, key[:10]), ErrInvalidKey))
+	assert.Nil(t, ring.Add("k1", AES, key))
+	assert.True(t, errors.Is(ring.Add("k1", AES, key), ErrInvalidKey))
+	assert.True(t, errors.Is(ring.SetActive("k2"), ErrKeyNotFound))
+	assert.True(t, errors.Is(ring.Remove("k2"), ErrKeyNotFound))
+
+	encrypted, _ := ring.Encrypt([]byte("x"))
+	// the header is authenticated
+	other := NewKeyRing()
+	assert.Nil(t, other.Add("k1", SM4, key))
+	_, err := other.Decrypt(encrypted)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = ring.Decrypt(tampered)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	for _, bad := range [][]byte{nil, {1, 1}, {2, 1, 1, 'a'}, {1, 1, 0}, {1, 1, 5, 'k'}} {
+		_, err = ring.Decrypt(bad)
+		assert.True(t, errors.Is(err, ErrInvalidFormat), "%x", bad)
+	}
+}
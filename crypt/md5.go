@@ -3,8 +3,6 @@ package crypt
 import (
 	"crypto/md5"
 	"encoding/hex"
-	"io"
-	"os"
 )
 
 // calculate md5 sum of string
@@ -15,16 +13,11 @@ func Md5(src string) string {
 }
 
 // calculate md5 sum of file
+// returns empty string on any error, use DigestFile to get the error
 func Md5File(path string) string {
-	f, err := os.Open(path)
+	sums, err := DigestFile(path, MD5)
 	if err != nil {
 		return ""
 	}
-	defer f.Close()
-
-	h := md5.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return ""
-	}
-	return hex.EncodeToString(h.Sum(nil))
+	return hex.EncodeToString(sums[MD5])
 }
@@ -0,0 +1,65 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// openssl enc加盐格式的前缀, 密文 = "Salted__" | salt(8) | ciphertext
+var openSSLMagic = []byte("Salted__")
+
+const openSSLSaltSize = 8
+
+// OpenSSLEncrypt 使用口令加密, 输出base64, 与openssl enc -aes-256-cbc -a -A及CryptoJS.AES.encrypt(text, passphrase)兼容
+// 参数:
+//   - data: 需要加密的原始数据
+//   - passphrase: 口令
+//   - h: 派生密钥使用的hash算法, CryptoJS和openssl enc -md md5使用MD5, openssl 1.1.0及以后默认使用SHA256
+//
+// 返回:
+//   - base64编码的密文, 不换行, 以"U2FsdGVkX1"开头
+//   - 错误信息(如果有)
+func OpenSSLEncrypt(data, passphrase []byte, h Hash) (string, error) {
+	salt := make([]byte, openSSLSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	return openSSLEncrypt(data, passphrase, salt, h)
+}
+
+// openSSLEncrypt 使用指定的盐加密
+func openSSLEncrypt(data, passphrase, salt []byte, h Hash) (string, error) {
+	key, iv, err := EVPBytesToKey(passphrase, salt, 32, 16, h)
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := AESEncrypt(data, key, iv, CBC, PKCS7)
+	if err != nil {
+		return "", err
+	}
+	out := make([]byte, 0, len(openSSLMagic)+len(salt)+len(encrypted))
+	out = append(append(append(out, openSSLMagic...), salt...), encrypted...)
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// OpenSSLDecrypt 解密OpenSSLEncrypt, openssl enc -aes-256-cbc -a或CryptoJS.AES.encrypt输出的base64密文
+// base64中的换行会被忽略, h需要与加密时一致, 口令错误时通常返回ErrInvalidPadding
+func OpenSSLDecrypt(src string, passphrase []byte, h Hash) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(src), ""))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(raw, openSSLMagic) || len(raw) < len(openSSLMagic)+openSSLSaltSize {
+		return nil, errors.New("not openssl salted format, Salted__ header not found")
+	}
+	salt := raw[len(openSSLMagic) : len(openSSLMagic)+openSSLSaltSize]
+	key, iv, err := EVPBytesToKey(passphrase, salt, 32, 16, h)
+	if err != nil {
+		return nil, err
+	}
+	return AESDecrypt(raw[len(openSSLMagic)+openSSLSaltSize:], key, iv, CBC, PKCS7)
+}
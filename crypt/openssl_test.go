@@ -0,0 +1,63 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenSSLVector(t *testing.T) {
+	plain := []byte("hello from cryptojs, 你好")
+	passphrase := []byte("zk-pass")
+
+	// openssl enc -aes-256-cbc -md md5 -S 0102030405060708 -pass pass:zk-pass, with Salted__ header
+	salt, _ := hex.DecodeString("0102030405060708")
+	expect := "U2FsdGVkX18BAgMEBQYHCBf4OOoj359/jrJFJReM3C3KcDQ1gZ4c1O3Mvx3KB9hf"
+	out, err := openSSLEncrypt(plain, passphrase, salt, MD5)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, out)
+	decrypted, err := OpenSSLDecrypt(expect, passphrase, MD5)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	// openssl 3 default digest sha256
+	decrypted, err = OpenSSLDecrypt("U2FsdGVkX1+hssPU5fYHGOWhIGJarvbdw33BExNv6ThzhiVP2hXZK62woGU21uIa", passphrase, SHA256)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	// openssl enc -a wraps lines at 64 characters
+	decrypted, err = OpenSSLDecrypt(`U2FsdGVkX1/6Jl84unSv9urJeBmUQ6CPfuxpKxNHV/b+bHd5MGwjulfAjiZvVRGW
+YQ0axEh/cxxb3N9l7qJHvYEtplbeibwD/z1MjUIWUCR8/Jmv/bc1XHwroeSpo5el
+XpUzHjQPD5v6MYndnxcWn8mkLf3fBRW8s4tumcMV9yU=
+`, passphrase, MD5)
+	assert.Nil(t, err)
+	assert.Equal(t, make([]byte, 100), decrypted)
+
+	// empty input
+	decrypted, err = OpenSSLDecrypt("U2FsdGVkX18N9Gm9b6a4MuFL8Y9o0rZoNRFWc3/7Pn0=", passphrase, MD5)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(decrypted))
+}
+
+func TestOpenSSLEncrypt(t *testing.T) {
+	plain := []byte("shared between browser and go")
+	out, err := OpenSSLEncrypt(plain, []byte("zk-pass"), MD5)
+	assert.Nil(t, err)
+	assert.Equal(t, "U2FsdGVkX1", out[:10])
+	another, _ := OpenSSLEncrypt(plain, []byte("zk-pass"), MD5)
+	assert.NotEqual(t, out, another)
+
+	decrypted, err := OpenSSLDecrypt(out, []byte("zk-pass"), MD5)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	// wrong passphrase fails the padding check with a probability of about 255/256
+	decrypted, err = OpenSSLDecrypt(out, []byte("zk-pasS"), MD5)
+	assert.True(t, errors.Is(err, ErrInvalidPadding) || (err == nil && string(decrypted) != string(plain)))
+	_, err = OpenSSLDecrypt("aGVsbG8gd29ybGQ=", []byte("zk-pass"), MD5)
+	assert.NotNil(t, err)
+	_, err = OpenSSLDecrypt("not base64", []byte("zk-pass"), MD5)
+	assert.NotNil(t, err)
+}
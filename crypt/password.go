@@ -0,0 +1,232 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// password hashing algorithm
+type PasswordAlgorithm string
+
+const (
+	//pbkdf2 with hmac-sha256
+	PBKDF2SHA256 PasswordAlgorithm = "pbkdf2-sha256"
+	//pbkdf2 with hmac-sm3
+	PBKDF2SM3 PasswordAlgorithm = "pbkdf2-sm3"
+	//bcrypt, passwords longer than 72 bytes are rejected
+	BCRYPT PasswordAlgorithm = "bcrypt"
+)
+
+// pbkdf2密码hash参数的范围, 防止恶意或损坏的hash导致长时间计算或截断的hash降低强度
+const (
+	passwordMaxIterations = 10000000
+	passwordMinSaltSize   = 16
+	passwordMinKeyLen     = 16
+	passwordMaxKeyLen     = 64
+)
+
+// PasswordOptions 密码hash的参数, 值为0的字段使用DefaultPasswordOptions中的值
+type PasswordOptions struct {
+	// hash算法
+	Algorithm PasswordAlgorithm
+	// pbkdf2迭代次数, 最大10000000
+	Iterations int
+	// pbkdf2盐的长度, 不能小于16
+	SaltSize int
+	// pbkdf2输出的长度, 16~64
+	KeyLen int
+	// bcrypt的cost, 4~31
+	Cost int
+}
+
+// DefaultPasswordOptions HashPassword的默认参数, VerifyPassword也据此判断是否需要重新hash
+// 需要提高强度时在程序启动时修改, 用户下次登录时即可按新参数重新hash
+var DefaultPasswordOptions = PasswordOptions{
+	Algorithm:  PBKDF2SHA256,
+	Iterations: 600000,
+	SaltSize:   16,
+	KeyLen:     32,
+	Cost:       12,
+}
+
+// withDefaults 使用默认值填充未设置的参数
+func (o PasswordOptions) withDefaults() PasswordOptions {
+	d := DefaultPasswordOptions
+	if o.Algorithm == "" {
+		o.Algorithm = d.Algorithm
+	}
+	if o.Iterations <= 0 {
+		o.Iterations = d.Iterations
+	}
+	if o.SaltSize <= 0 {
+		o.SaltSize = d.SaltSize
+	}
+	if o.KeyLen <= 0 {
+		o.KeyLen = d.KeyLen
+	}
+	if o.Cost <= 0 {
+		o.Cost = d.Cost
+	}
+	return o
+}
+
+// hash 返回pbkdf2使用的hash算法
+func (a PasswordAlgorithm) hash() (Hash, error) {
+	switch a {
+	case PBKDF2SHA256:
+		return SHA256, nil
+	case PBKDF2SM3:
+		return SM3, nil
+	default:
+		return "", fmt.Errorf("%w: password algorithm %s", ErrInvalidAlgorithm, a)
+	}
+}
+
+// HashPassword 计算密码的hash, 返回包含算法, 参数和盐的字符串, opts为nil时使用DefaultPasswordOptions
+//   - pbkdf2: $pbkdf2-sha256$i=600000$<base64 salt>$<base64 hash>, base64不带填充
+//   - bcrypt: $2a$12$..., 与其他语言的bcrypt实现兼容
+func HashPassword(password string, opts *PasswordOptions) (string, error) {
+	o := DefaultPasswordOptions
+	if opts != nil {
+		o = *opts
+	}
+	o = o.withDefaults()
+
+	if o.Algorithm == BCRYPT {
+		out, err := bcrypt.GenerateFromPassword([]byte(password), o.Cost)
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	}
+
+	h, err := o.Algorithm.hash()
+	if err != nil {
+		return "", err
+	}
+	if err = checkPBKDF2Params(o.Iterations, o.SaltSize, o.KeyLen); err != nil {
+		return "", err
+	}
+	salt := make([]byte, o.SaltSize)
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	key, err := PBKDF2([]byte(password), salt, o.Iterations, o.KeyLen, h)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$%s$i=%d$%s$%s", o.Algorithm, o.Iterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword 校验密码是否与HashPassword的结果一致, 使用常量时间比较
+// 不接受无盐的Md5, 迁移旧数据时使用VerifyLegacyMd5
+// 密码正确且hash的算法或参数弱于DefaultPasswordOptions时needsRehash为true, 此时应使用HashPassword重新hash并保存
+//
+//	ok, needsRehash, err := VerifyPassword(pw, user.Password)
+//	if ok && needsRehash {
+//		user.Password, err = HashPassword(pw, nil)
+//	}
+func VerifyPassword(password, encoded string) (ok bool, needsRehash bool, err error) {
+	d := DefaultPasswordOptions.withDefaults()
+
+	switch {
+	case strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$"):
+		cost, err := bcrypt.Cost([]byte(encoded))
+		if err != nil {
+			return false, false, fmt.Errorf("%w: %s", ErrInvalidHash, err.Error())
+		}
+		err = bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		return true, d.Algorithm != BCRYPT || cost < d.Cost, nil
+	case strings.HasPrefix(encoded, "$pbkdf2-"):
+		return verifyPBKDF2(password, encoded, d)
+	}
+	return false, false, fmt.Errorf("%w: unrecognized password hash", ErrInvalidHash)
+}
+
+// VerifyLegacyMd5 校验旧数据中Md5(password)的32位hex, 不区分大小写, 使用常量时间比较
+// 无盐的Md5很容易被破解, 只用于迁移: 校验通过后应立即使用HashPassword重新hash并保存
+//
+//	if !strings.HasPrefix(user.Password, "$") {
+//		ok, err := VerifyLegacyMd5(pw, user.Password)
+//		if ok {
+//			user.Password, err = HashPassword(pw, nil)
+//		}
+//	}
+func VerifyLegacyMd5(password, encoded string) (bool, error) {
+	if len(encoded) != 32 {
+		return false, fmt.Errorf("%w: md5 hash must be 32 hex characters", ErrInvalidHash)
+	}
+	if _, err := hex.DecodeString(encoded); err != nil {
+		return false, fmt.Errorf("%w: %s", ErrInvalidHash, err.Error())
+	}
+	return subtle.ConstantTimeCompare([]byte(Md5(password)), []byte(strings.ToLower(encoded))) == 1, nil
+}
+
+// checkPBKDF2Params 检查pbkdf2的迭代次数, 盐和输出的长度
+func checkPBKDF2Params(iter, saltSize, keyLen int) error {
+	if iter < 1 || iter > passwordMaxIterations {
+		return fmt.Errorf("%w: pbkdf2 iterations %d", ErrInvalidHash, iter)
+	}
+	if saltSize < passwordMinSaltSize {
+		return fmt.Errorf("%w: pbkdf2 salt length %d", ErrInvalidHash, saltSize)
+	}
+	if keyLen < passwordMinKeyLen || keyLen > passwordMaxKeyLen {
+		return fmt.Errorf("%w: pbkdf2 hash length %d", ErrInvalidHash, keyLen)
+	}
+	return nil
+}
+
+// verifyPBKDF2 校验pbkdf2格式的密码hash
+func verifyPBKDF2(password, encoded string, d PasswordOptions) (bool, bool, error) {
+	// "", alg, i=N, salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || !strings.HasPrefix(parts[2], "i=") {
+		return false, false, fmt.Errorf("%w: malformed pbkdf2 hash", ErrInvalidHash)
+	}
+	alg := PasswordAlgorithm(parts[1])
+	h, err := alg.hash()
+	if err != nil {
+		return false, false, err
+	}
+	iter, err := strconv.Atoi(parts[2][2:])
+	if err != nil {
+		return false, false, fmt.Errorf("%w: malformed pbkdf2 iterations", ErrInvalidHash)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, false, fmt.Errorf("%w: malformed pbkdf2 salt", ErrInvalidHash)
+	}
+	expect, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, fmt.Errorf("%w: malformed pbkdf2 hash", ErrInvalidHash)
+	}
+	if err = checkPBKDF2Params(iter, len(salt), len(expect)); err != nil {
+		return false, false, err
+	}
+
+	key, err := PBKDF2([]byte(password), salt, iter, len(expect), h)
+	if err != nil {
+		return false, false, err
+	}
+	if subtle.ConstantTimeCompare(key, expect) != 1 {
+		return false, false, nil
+	}
+	needsRehash := alg != d.Algorithm || iter < d.Iterations || len(salt) < d.SaltSize || len(expect) < d.KeyLen
+	return true, needsRehash, nil
+}
@@ -0,0 +1,93 @@
+package crypt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPassword(t *testing.T) {
+	for _, opts := range []*PasswordOptions{
+		{Algorithm: PBKDF2SHA256, Iterations: 1000},
+		{Algorithm: PBKDF2SM3, Iterations: 1000, SaltSize: 16, KeyLen: 16},
+		{Algorithm: BCRYPT, Cost: bcrypt.MinCost},
+	} {
+		encoded, err := HashPassword("correct horse", opts)
+		assert.Nil(t, err, "%s", opts.Algorithm)
+		another, _ := HashPassword("correct horse", opts)
+		assert.NotEqual(t, encoded, another, "%s", opts.Algorithm)
+
+		ok, _, err := VerifyPassword("correct horse", encoded)
+		assert.Nil(t, err, "%s", opts.Algorithm)
+		assert.True(t, ok, "%s", opts.Algorithm)
+		ok, needsRehash, err := VerifyPassword("correct horsE", encoded)
+		assert.Nil(t, err, "%s", opts.Algorithm)
+		assert.False(t, ok, "%s", opts.Algorithm)
+		assert.False(t, needsRehash, "%s", opts.Algorithm)
+	}
+
+	encoded, err := HashPassword("correct horse", &PasswordOptions{Iterations: 1000})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$pbkdf2-sha256$i=1000$"))
+
+	_, err = HashPassword("correct horse", &PasswordOptions{KeyLen: 8})
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+	_, err = HashPassword("correct horse", &PasswordOptions{SaltSize: 8})
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+	_, err = HashPassword("correct horse", &PasswordOptions{Algorithm: "argon2id"})
+	assert.True(t, errors.Is(err, ErrInvalidAlgorithm))
+	_, err = HashPassword(strings.Repeat("x", 73), &PasswordOptions{Algorithm: BCRYPT, Cost: bcrypt.MinCost})
+	assert.NotNil(t, err)
+}
+
+func TestVerifyPassword(t *testing.T) {
+	// python hashlib.pbkdf2_hmac("sha256", b"correct horse", b"0123456789abcdef", 1000, 32)
+	encoded := "$pbkdf2-sha256$i=1000$MDEyMzQ1Njc4OWFiY2RlZg$cBg8D2DungRB9k76szThf5ehfyBz991ay6PT8Srwk4M"
+	ok, needsRehash, err := VerifyPassword("correct horse", encoded)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+
+	defaults := DefaultPasswordOptions
+	defer func() { DefaultPasswordOptions = defaults }()
+	DefaultPasswordOptions.Iterations = 1000
+	ok, needsRehash, _ = VerifyPassword("correct horse", encoded)
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+
+	// stronger defaults or another algorithm require rehash
+	bcryptHash, _ := HashPassword("correct horse", &PasswordOptions{Algorithm: BCRYPT, Cost: bcrypt.MinCost})
+	ok, needsRehash, _ = VerifyPassword("correct horse", bcryptHash)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+	DefaultPasswordOptions.Algorithm, DefaultPasswordOptions.Cost = BCRYPT, bcrypt.MinCost
+	ok, needsRehash, _ = VerifyPassword("correct horse", bcryptHash)
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+
+	// legacy md5 needs an explicit call
+	_, _, err = VerifyPassword("correct horse", Md5("correct horse"))
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+	ok, err = VerifyLegacyMd5("correct horse", strings.ToUpper(Md5("correct horse")))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, _ = VerifyLegacyMd5("correct horsE", Md5("correct horse"))
+	assert.False(t, ok)
+	_, err = VerifyLegacyMd5("correct horse", strings.Repeat("x", 32))
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+
+	for _, bad := range []string{"", "plain", "$pbkdf2-sha256$i=x$a$b", "$pbkdf2-md5$i=1$MDEy$MDEy", "$pbkdf2-sha256$i=1000$MDEy", "$2a$04$short",
+		// iterations, salt and hash length out of range
+		"$pbkdf2-sha256$i=0$MDEyMzQ1Njc4OWFiY2RlZg$cBg8D2DungRB9k76szThf5ehfyBz991ay6PT8Srwk4M",
+		"$pbkdf2-sha256$i=2147483647$MDEyMzQ1Njc4OWFiY2RlZg$cBg8D2DungRB9k76szThf5ehfyBz991ay6PT8Srwk4M",
+		"$pbkdf2-sha256$i=1000$MDEyMzQ1Njc$cBg8D2DungRB9k76szThf5ehfyBz991ay6PT8Srwk4M",
+		"$pbkdf2-sha256$i=1000$MDEyMzQ1Njc4OWFiY2RlZg$cBg8D2Dung",
+		"$pbkdf2-sha256$i=1000$MDEyMzQ1Njc4OWFiY2RlZg$" + strings.Repeat("A", 100),
+	} {
+		_, _, err = VerifyPassword("correct horse", bad)
+		assert.NotNil(t, err, "%s", bad)
+	}
+}
@@ -0,0 +1,21 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+)
+
+// generate rsa private key, bits must be at least 2048
+func GenerateRSAKey(bits int) (*rsa.PrivateKey, error) {
+	if bits < 2048 {
+		return nil, fmt.Errorf("%w: rsa key size %d is less than 2048 bits", ErrInvalidKey, bits)
+	}
+	return rsa.GenerateKey(rand.Reader, bits)
+}
+
+// marshal rsa private key to PKCS#1, type "RSA PRIVATE KEY" in pem format
+func MarshalPKCS1PrivateKey(key *rsa.PrivateKey, format KeyFormat) ([]byte, error) {
+	return encodeKey(x509.MarshalPKCS1PrivateKey(key), "RSA PRIVATE KEY", format)
+}
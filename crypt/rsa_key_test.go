@@ -0,0 +1,56 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateRSAKey(t *testing.T) {
+	key, err := GenerateRSAKey(2048)
+	assert.Nil(t, err)
+	assert.Equal(t, 2048, key.N.BitLen())
+
+	_, err = GenerateRSAKey(1024)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	for _, marshal := range []func(KeyFormat) ([]byte, error){
+		func(f KeyFormat) ([]byte, error) { return MarshalPKCS1PrivateKey(key, f) },
+		func(f KeyFormat) ([]byte, error) { return MarshalPKCS8PrivateKey(key, f) },
+	} {
+		out, err := marshal(PEM)
+		assert.Nil(t, err)
+		parsed, err := ParsePrivateKey(out)
+		assert.Nil(t, err)
+		assert.True(t, key.Equal(parsed))
+
+		line, err := marshal(PEMLINE)
+		assert.Nil(t, err)
+		assert.False(t, bytes.ContainsAny(line, "\n-"))
+
+		der, err := marshal(DER)
+		assert.Nil(t, err)
+		assert.True(t, bytes.Contains(out, line[:64]))
+		assert.NotEmpty(t, der)
+	}
+
+	der, err := MarshalPKCS1PrivateKey(key, DER)
+	assert.Nil(t, err)
+	parsed, err := x509.ParsePKCS1PrivateKey(der)
+	assert.Nil(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	for _, f := range []KeyFormat{PEM, PEMLINE} {
+		out, err := MarshalPKIXPublicKey(&key.PublicKey, f)
+		assert.Nil(t, err)
+		pub, err := ParsePublicKey(out)
+		assert.Nil(t, err)
+		assert.True(t, key.PublicKey.Equal(pub))
+	}
+
+	_, err = MarshalPKIXPublicKey(&key.PublicKey, "JWK")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,60 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+)
+
+// encrypt data with rsa public key in OAEP padding
+// data longer than k-2*hLen-2 bytes is split into blocks like PublicEncrypt
+func PublicEncryptOAEP(key *rsa.PublicKey, src []byte, h Hash, label []byte) ([]byte, error) {
+	newHash, err := h.macHash()
+	if err != nil {
+		return nil, err
+	}
+	hh := newHash()
+	k := key.Size() - 2*hh.Size() - 2
+	if k <= 0 {
+		return nil, errDataToLarge
+	}
+
+	out := bytes.NewBuffer(nil)
+	for {
+		n := k
+		if n > len(src) {
+			n = len(src)
+		}
+		b, err := rsa.EncryptOAEP(hh, rand.Reader, key, src[:n], label)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(b)
+		if src = src[n:]; len(src) == 0 {
+			return out.Bytes(), nil
+		}
+	}
+}
+
+// decrypt data with rsa private key in OAEP padding
+func PrivateDecryptOAEP(key *rsa.PrivateKey, src []byte, h Hash, label []byte) ([]byte, error) {
+	newHash, err := h.macHash()
+	if err != nil {
+		return nil, err
+	}
+	k := key.Size()
+	if len(src) == 0 || len(src)%k != 0 {
+		return nil, errDataLen
+	}
+
+	hh := newHash()
+	out := bytes.NewBuffer(nil)
+	for ; len(src) > 0; src = src[k:] {
+		b, err := rsa.DecryptOAEP(hh, rand.Reader, key, src[:k], label)
+		if err != nil {
+			return nil, errDecryption
+		}
+		out.Write(b)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,201 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"hash"
+	"math/big"
+)
+
+// sm3未在crypto包中注册, PKCS1v15签名时需要自己拼接DigestInfo前缀
+// SEQUENCE { SEQUENCE { OID 1.2.156.10197.1.401, NULL }, OCTET STRING(32) }
+var sm3DigestInfoPrefix = []byte{0x30, 0x30, 0x30, 0x0c, 0x06, 0x08, 0x2a, 0x81, 0x1c, 0xcf, 0x55, 0x01, 0x83, 0x11, 0x05, 0x00, 0x04, 0x20}
+
+var errPSSKeySize = errors.New("key size too small for PSS signature")
+
+// signDigest 计算用于签名的摘要
+func signDigest(h Hash, data []byte) ([]byte, error) {
+	if _, err := h.signHash(); err != nil {
+		return nil, err
+	}
+	return h.digest(data)
+}
+
+// sign data with rsa private key in PKCS#1 v1.5 padding
+// data is hashed with h before signing
+func SignPKCS1v15(key *rsa.PrivateKey, data []byte, h Hash) ([]byte, error) {
+	digest, err := signDigest(h, data)
+	if err != nil {
+		return nil, err
+	}
+	if h == SM3 {
+		return rsa.SignPKCS1v15(rand.Reader, key, 0, append(sm3DigestInfoPrefix, digest...))
+	}
+	return rsa.SignPKCS1v15(rand.Reader, key, h.cryptoHash(), digest)
+}
+
+// verify PKCS#1 v1.5 signature with rsa public key, returns ErrVerification if signature is invalid
+func VerifyPKCS1v15(key *rsa.PublicKey, data, sig []byte, h Hash) error {
+	digest, err := signDigest(h, data)
+	if err != nil {
+		return err
+	}
+	if h == SM3 {
+		err = rsa.VerifyPKCS1v15(key, 0, append(sm3DigestInfoPrefix, digest...), sig)
+	} else {
+		err = rsa.VerifyPKCS1v15(key, h.cryptoHash(), digest, sig)
+	}
+	if err != nil {
+		return ErrVerification
+	}
+	return nil
+}
+
+// sign data with rsa private key in PSS padding
+// salt length equals hash length, MGF1 uses the same hash as the message
+func SignPSS(key *rsa.PrivateKey, data []byte, h Hash) ([]byte, error) {
+	digest, err := signDigest(h, data)
+	if err != nil {
+		return nil, err
+	}
+	if h != SM3 {
+		return rsa.SignPSS(rand.Reader, key, h.cryptoHash(), digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	}
+
+	newHash, _ := h.signHash()
+	salt := make([]byte, len(digest))
+	if _, err = rand.Read(salt); err != nil {
+		return nil, err
+	}
+	em, err := emsaPSSEncode(digest, key.N.BitLen()-1, salt, newHash())
+	if err != nil {
+		return nil, err
+	}
+	c, err := decryptAndCheck(rand.Reader, key, new(big.Int).SetBytes(em))
+	if err != nil {
+		return nil, err
+	}
+	return leftPad(c.Bytes(), key.Size()), nil
+}
+
+// verify PSS signature with rsa public key, salt length is detected automatically
+// returns ErrVerification if signature is invalid
+func VerifyPSS(key *rsa.PublicKey, data, sig []byte, h Hash) error {
+	digest, err := signDigest(h, data)
+	if err != nil {
+		return err
+	}
+	if h != SM3 {
+		if rsa.VerifyPSS(key, h.cryptoHash(), digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) != nil {
+			return ErrVerification
+		}
+		return nil
+	}
+
+	if len(sig) != key.Size() {
+		return ErrVerification
+	}
+	m := new(big.Int).SetBytes(sig)
+	if m.Cmp(key.N) >= 0 {
+		return ErrVerification
+	}
+	m.Exp(m, big.NewInt(int64(key.E)), key.N)
+	emBits := key.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	if len(m.Bytes()) > emLen {
+		return ErrVerification
+	}
+	newHash, _ := h.signHash()
+	return emsaPSSVerify(digest, leftPad(m.Bytes(), emLen), emBits, newHash())
+}
+
+// 从crypto/rsa复制, EMSA-PSS编码
+func emsaPSSEncode(mHash []byte, emBits int, salt []byte, h hash.Hash) ([]byte, error) {
+	hLen := h.Size()
+	sLen := len(salt)
+	emLen := (emBits + 7) / 8
+	if emLen < hLen+sLen+2 {
+		return nil, errPSSKeySize
+	}
+
+	em := make([]byte, emLen)
+	psLen := emLen - sLen - hLen - 2
+	db := em[:psLen+1+sLen]
+	hh := em[psLen+1+sLen : emLen-1]
+
+	var prefix [8]byte
+	h.Write(prefix[:])
+	h.Write(mHash)
+	h.Write(salt)
+	hh = h.Sum(hh[:0])
+	h.Reset()
+
+	db[psLen] = 0x01
+	copy(db[psLen+1:], salt)
+	mgf1XOR(db, h, hh)
+	db[0] &= 0xff >> (8*emLen - emBits)
+	em[emLen-1] = 0xbc
+	return em, nil
+}
+
+// 从crypto/rsa复制, EMSA-PSS校验, 自动识别salt长度
+func emsaPSSVerify(mHash, em []byte, emBits int, h hash.Hash) error {
+	hLen := h.Size()
+	emLen := (emBits + 7) / 8
+	if emLen != len(em) || emLen < hLen+2 || em[emLen-1] != 0xbc {
+		return ErrVerification
+	}
+
+	db := em[:emLen-hLen-1]
+	hh := em[emLen-hLen-1 : emLen-1]
+	bitMask := byte(0xff >> (8*emLen - emBits))
+	if em[0]&^bitMask != 0 {
+		return ErrVerification
+	}
+	mgf1XOR(db, h, hh)
+	db[0] &= bitMask
+
+	psLen := bytes.IndexByte(db, 0x01)
+	if psLen < 0 {
+		return ErrVerification
+	}
+	for _, b := range db[:psLen] {
+		if b != 0 {
+			return ErrVerification
+		}
+	}
+	salt := db[psLen+1:]
+
+	var prefix [8]byte
+	h.Write(prefix[:])
+	h.Write(mHash)
+	h.Write(salt)
+	if !bytes.Equal(h.Sum(nil), hh) {
+		return ErrVerification
+	}
+	return nil
+}
+
+// 从crypto/rsa复制, MGF1掩码生成函数
+func mgf1XOR(out []byte, h hash.Hash, seed []byte) {
+	var counter [4]byte
+	var digest []byte
+	done := 0
+	for done < len(out) {
+		h.Write(seed)
+		h.Write(counter[:])
+		digest = h.Sum(digest[:0])
+		h.Reset()
+		for i := 0; i < len(digest) && done < len(out); i++ {
+			out[done] ^= digest[i]
+			done++
+		}
+		for i := 3; i >= 0; i-- {
+			if counter[i]++; counter[i] != 0 {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+var testRSAKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+func TestRSASign(t *testing.T) {
+	data := []byte("partner callback body")
+	for _, h := range []Hash{SHA256, SHA384, SHA512, SM3} {
+		sig, err := SignPKCS1v15(testRSAKey, data, h)
+		assert.Nil(t, err, "%s", h)
+		assert.Nil(t, VerifyPKCS1v15(&testRSAKey.PublicKey, data, sig, h), "%s", h)
+		assert.True(t, errors.Is(VerifyPKCS1v15(&testRSAKey.PublicKey, []byte("tampered"), sig, h), ErrVerification), "%s", h)
+
+		sig, err = SignPSS(testRSAKey, data, h)
+		assert.Nil(t, err, "%s", h)
+		assert.Nil(t, VerifyPSS(&testRSAKey.PublicKey, data, sig, h), "%s", h)
+		assert.True(t, errors.Is(VerifyPSS(&testRSAKey.PublicKey, []byte("tampered"), sig, h), ErrVerification), "%s", h)
+	}
+
+	_, err := SignPSS(testRSAKey, data, "MD4")
+	assert.True(t, errors.Is(err, ErrInvalidHash))
+}
+
+func TestSM3DigestInfo(t *testing.T) {
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		Digest    []byte
+	}
+	digest := sm3.Sm3Sum([]byte("abc"))
+	rest, err := asn1.Unmarshal(append(sm3DigestInfoPrefix, digest...), &info)
+	assert.Nil(t, err)
+	assert.Empty(t, rest)
+	assert.Equal(t, asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 401}, info.Algorithm.Algorithm)
+	assert.Equal(t, digest, info.Digest)
+}
+
+func TestEMSAPSS(t *testing.T) {
+	// the hand written PSS encoding must be compatible with crypto/rsa
+	digest := sha256.Sum256([]byte("pss"))
+	salt := make([]byte, 32)
+	rand.Read(salt)
+	em, err := emsaPSSEncode(digest[:], testRSAKey.N.BitLen()-1, salt, sha256.New())
+	assert.Nil(t, err)
+	c, err := decrypt(rand.Reader, testRSAKey, new(big.Int).SetBytes(em))
+	assert.Nil(t, err)
+	sig := leftPad(c.Bytes(), testRSAKey.Size())
+	assert.Nil(t, rsa.VerifyPSS(&testRSAKey.PublicKey, crypto.SHA256, digest[:], sig, nil))
+
+	sig, err = rsa.SignPSS(rand.Reader, testRSAKey, crypto.SHA256, digest[:], nil)
+	assert.Nil(t, err)
+	m := new(big.Int).SetBytes(sig)
+	m.Exp(m, big.NewInt(int64(testRSAKey.E)), testRSAKey.N)
+	emBits := testRSAKey.N.BitLen() - 1
+	assert.Nil(t, emsaPSSVerify(digest[:], leftPad(m.Bytes(), (emBits+7)/8), emBits, sha256.New()))
+}
+
+func TestRSASignFault(t *testing.T) {
+	// a broken CRT value must not leak a faulty signature
+	faulty := *testRSAKey
+	faulty.Precomputed.Dp = new(big.Int).Add(testRSAKey.Precomputed.Dp, bigOne)
+	_, err := SignPSS(&faulty, []byte("abc"), SM3)
+	assert.NotNil(t, err)
+	_, err = decryptAndCheck(rand.Reader, &faulty, big.NewInt(2))
+	assert.NotNil(t, err)
+
+	sig, err := SignPSS(testRSAKey, []byte("abc"), SM3)
+	assert.Nil(t, err)
+	assert.Nil(t, VerifyPSS(&testRSAKey.PublicKey, []byte("abc"), sig, SM3))
+}
+
+func TestRSAOAEP(t *testing.T) {
+	label := []byte("orders")
+	for _, size := range []int{0, 10, 190, 191, 1000} {
+		data := make([]byte, size)
+		rand.Read(data)
+		for _, h := range []Hash{SHA256, SM3} {
+			encrypted, err := PublicEncryptOAEP(&testRSAKey.PublicKey, data, h, label)
+			assert.Nil(t, err)
+			decrypted, err := PrivateDecryptOAEP(testRSAKey, encrypted, h, label)
+			assert.Nil(t, err)
+			assert.True(t, bytes.Equal(data, decrypted), "%s %d", h, size)
+
+			_, err = PrivateDecryptOAEP(testRSAKey, encrypted, h, []byte("users"))
+			assert.NotNil(t, err)
+		}
+	}
+
+	// compatible with crypto/rsa when data fits in one block
+	encrypted, err := PublicEncryptOAEP(&testRSAKey.PublicKey, []byte("hello"), SHA256, nil)
+	assert.Nil(t, err)
+	decrypted, err := rsa.DecryptOAEP(sha256.New(), nil, testRSAKey, encrypted, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("hello"), decrypted)
+}
@@ -0,0 +1,285 @@
+package crypt
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"github.com/tjfoc/gmsm/sm2"
+	gmx509 "github.com/tjfoc/gmsm/x509"
+)
+
+// sm2 ciphertext order
+type SM2Order string
+
+const (
+	//C1|C3|C2, GM/T 0003-2012 and BouncyCastle SM2Engine.Mode.C1C3C2
+	C1C3C2 SM2Order = "C1C3C2"
+	//C1|C2|C3, the legacy order and BouncyCastle default
+	C1C2C3 SM2Order = "C1C2C3"
+)
+
+// sm2密文C1的长度: 0x04 | x(32) | y(32)
+const sm2C1Size = 65
+
+// sm2密文的ASN.1结构, GM/T 0009-2012
+type sm2CipherC1C3C2 struct {
+	X, Y       *big.Int
+	Hash       []byte
+	CipherText []byte
+}
+
+// C1C2C3顺序的ASN.1结构
+type sm2CipherC1C2C3 struct {
+	X, Y       *big.Int
+	CipherText []byte
+	Hash       []byte
+}
+
+var (
+	oidPublicKeyEC   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveSM2 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+)
+
+// SEC1格式的私钥, RFC 5915
+type sm2ECPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// PKCS#8格式的私钥, RFC 5208
+type sm2PKCS8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// PKIX格式的公钥
+type sm2PKIXPublicKey struct {
+	Algo      pkix.AlgorithmIdentifier
+	BitString asn1.BitString
+}
+
+// parseSM2SEC1 读取SEC1格式的sm2私钥
+// gmx509不检查曲线, 其他曲线的私钥也会被当作sm2私钥, 因此先检查曲线的oid
+// 在PKCS#8中时曲线由外层指定, inPKCS8为true时允许省略oid
+func parseSM2SEC1(der []byte, inPKCS8 bool) (*sm2.PrivateKey, error) {
+	var k sm2ECPrivateKey
+	if rest, err := asn1.Unmarshal(der, &k); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("%w: malformed sm2 private key", ErrInvalidKeyFormat)
+	}
+	if !k.NamedCurveOID.Equal(oidNamedCurveSM2) && !(inPKCS8 && len(k.NamedCurveOID) == 0) {
+		return nil, fmt.Errorf("%w: not a sm2 private key", ErrInvalidKeyFormat)
+	}
+	key, err := gmx509.ParseSm2PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyFormat, err.Error())
+	}
+	if key.D.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: invalid sm2 private key", ErrInvalidKeyFormat)
+	}
+	return key, nil
+}
+
+// parseSM2PKCS8 读取PKCS#8格式的sm2私钥, 算法参数中的曲线必须是sm2
+func parseSM2PKCS8(der []byte) (*sm2.PrivateKey, error) {
+	var k sm2PKCS8
+	if rest, err := asn1.Unmarshal(der, &k); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("%w: malformed sm2 private key", ErrInvalidKeyFormat)
+	}
+	if !k.Algo.Algorithm.Equal(oidPublicKeyEC) || !isSM2Curve(k.Algo.Parameters.FullBytes) {
+		return nil, fmt.Errorf("%w: not a sm2 private key", ErrInvalidKeyFormat)
+	}
+	return parseSM2SEC1(k.PrivateKey, true)
+}
+
+// parseSM2PKIX 读取PKIX格式的sm2公钥, 检查曲线的oid和公钥点
+// gmx509不检查曲线, 公钥点无效时返回X, Y为nil的公钥
+func parseSM2PKIX(der []byte) (*sm2.PublicKey, error) {
+	var k sm2PKIXPublicKey
+	if rest, err := asn1.Unmarshal(der, &k); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("%w: malformed sm2 public key", ErrInvalidKeyFormat)
+	}
+	if !k.Algo.Algorithm.Equal(oidPublicKeyEC) || !isSM2Curve(k.Algo.Parameters.FullBytes) {
+		return nil, fmt.Errorf("%w: not a sm2 public key", ErrInvalidKeyFormat)
+	}
+	key, err := gmx509.ParseSm2PublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyFormat, err.Error())
+	}
+	if key.X == nil || !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, fmt.Errorf("%w: invalid sm2 public key", ErrInvalidKeyFormat)
+	}
+	return key, nil
+}
+
+// isSM2Curve 判断算法参数是否为sm2曲线的oid
+func isSM2Curve(params []byte) bool {
+	var oid asn1.ObjectIdentifier
+	rest, err := asn1.Unmarshal(params, &oid)
+	return err == nil && len(rest) == 0 && oid.Equal(oidNamedCurveSM2)
+}
+
+// marshalSM2SEC1 将sm2私钥编码为SEC1格式, 与openssl ec的输出一致
+func marshalSM2SEC1(key *sm2.PrivateKey) ([]byte, error) {
+	if key.Curve.Params() != sm2.P256Sm2().Params() {
+		return nil, fmt.Errorf("%w: not a sm2 private key", ErrInvalidKey)
+	}
+	d := make([]byte, (key.Curve.Params().N.BitLen()+7)/8)
+	pub := elliptic.Marshal(key.Curve, key.X, key.Y)
+	return asn1.Marshal(sm2ECPrivateKey{
+		Version:       1,
+		PrivateKey:    key.D.FillBytes(d),
+		NamedCurveOID: oidNamedCurveSM2,
+		PublicKey:     asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+	})
+}
+
+// gmMode 转换为gmsm中的密文顺序
+func (o SM2Order) gmMode() (int, error) {
+	switch o {
+	case C1C3C2:
+		return sm2.C1C3C2, nil
+	case C1C2C3:
+		return sm2.C1C2C3, nil
+	default:
+		return 0, fmt.Errorf("%w: sm2 cipher order %s", ErrInvalidMode, o)
+	}
+}
+
+// generate sm2 private key
+func GenerateSM2Key() (*sm2.PrivateKey, error) {
+	return sm2.GenerateKey(rand.Reader)
+}
+
+// 读取sm2私钥, 支持PKCS#8和SEC1("EC PRIVATE KEY")格式
+// 私钥可以是PEM, 也可以是没有前缀后缀的单行base64
+func ParseSM2PrivateKey(in []byte) (*sm2.PrivateKey, error) {
+	der, err := decodeKey(in)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := parseSM2PKCS8(der); err == nil {
+		return key, nil
+	}
+	return parseSM2SEC1(der, false)
+}
+
+// 读取sm2公钥(PKIX), 公钥可以没有如 -----BEGIN PUBLIC KEY-----的前缀后缀
+func ParseSM2PublicKey(in []byte) (*sm2.PublicKey, error) {
+	der, err := decodeKey(in)
+	if err != nil {
+		return nil, err
+	}
+	return parseSM2PKIX(der)
+}
+
+// marshal sm2 private key to PKCS#8, type "PRIVATE KEY" in pem format
+func MarshalSM2PrivateKey(key *sm2.PrivateKey, format KeyFormat) ([]byte, error) {
+	der, err := gmx509.MarshalSm2UnecryptedPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return encodeKey(der, "PRIVATE KEY", format)
+}
+
+// marshal sm2 public key to PKIX, type "PUBLIC KEY" in pem format
+func MarshalSM2PublicKey(key *sm2.PublicKey, format KeyFormat) ([]byte, error) {
+	der, err := gmx509.MarshalSm2PublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return encodeKey(der, "PUBLIC KEY", format)
+}
+
+// sign data with sm2 private key, returns ASN.1 DER encoded signature
+// uid is the user id used to calculate ZA, default "1234567812345678" is used when empty
+func SignSM2(key *sm2.PrivateKey, data, uid []byte) ([]byte, error) {
+	r, s, err := sm2.Sm2Sign(key, data, uid, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return sm2.SignDigitToSignData(r, s)
+}
+
+// verify ASN.1 DER encoded sm2 signature, returns ErrVerification if signature is invalid
+func VerifySM2(key *sm2.PublicKey, data, sig, uid []byte) error {
+	r, s, err := sm2.SignDataToSignDigit(sig)
+	if err != nil || !sm2.Sm2Verify(key, data, uid, r, s) {
+		return ErrVerification
+	}
+	return nil
+}
+
+// encrypt data with sm2 public key
+// raw output is 0x04|x|y followed by C3/C2 in the given order, as BouncyCastle does
+// asn1 output is SEQUENCE { x, y, C3, C2 } (fields follow the given order)
+func SM2Encrypt(key *sm2.PublicKey, data []byte, order SM2Order, asn1Output bool) ([]byte, error) {
+	mode, err := order.gmMode()
+	if err != nil {
+		return nil, err
+	}
+	out, err := sm2.Encrypt(key, data, rand.Reader, mode)
+	if err != nil || !asn1Output {
+		return out, err
+	}
+
+	x := new(big.Int).SetBytes(out[1:33])
+	y := new(big.Int).SetBytes(out[33:sm2C1Size])
+	if order == C1C3C2 {
+		return asn1.Marshal(sm2CipherC1C3C2{x, y, out[sm2C1Size : sm2C1Size+32], out[sm2C1Size+32:]})
+	}
+	return asn1.Marshal(sm2CipherC1C2C3{x, y, out[sm2C1Size : len(out)-32], out[len(out)-32:]})
+}
+
+// decrypt data with sm2 private key, order and asn1Input must match the encryption side
+func SM2Decrypt(key *sm2.PrivateKey, data []byte, order SM2Order, asn1Input bool) ([]byte, error) {
+	mode, err := order.gmMode()
+	if err != nil {
+		return nil, err
+	}
+
+	if asn1Input {
+		var x, y *big.Int
+		var c2, c3 []byte
+		if order == C1C3C2 {
+			var c sm2CipherC1C3C2
+			if _, err = asn1.Unmarshal(data, &c); err != nil {
+				return nil, errDecryption
+			}
+			x, y, c2, c3 = c.X, c.Y, c.CipherText, c.Hash
+		} else {
+			var c sm2CipherC1C2C3
+			if _, err = asn1.Unmarshal(data, &c); err != nil {
+				return nil, errDecryption
+			}
+			x, y, c2, c3 = c.X, c.Y, c.CipherText, c.Hash
+		}
+		if x == nil || y == nil || len(c3) != 32 || x.BitLen() > 256 || y.BitLen() > 256 {
+			return nil, errDecryption
+		}
+		raw := make([]byte, sm2C1Size, sm2C1Size+len(c2)+len(c3))
+		raw[0] = 0x04
+		x.FillBytes(raw[1:33])
+		y.FillBytes(raw[33:sm2C1Size])
+		if order == C1C3C2 {
+			data = append(append(raw, c3...), c2...)
+		} else {
+			data = append(append(raw, c2...), c3...)
+		}
+	}
+
+	if len(data) < sm2C1Size+32 || data[0] != 0x04 {
+		return nil, errDecryption
+	}
+	out, err := sm2.Decrypt(key, data, mode)
+	if err != nil {
+		return nil, errDecryption
+	}
+	return out, nil
+}
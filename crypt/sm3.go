@@ -0,0 +1,55 @@
+package crypt
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"hash"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// calculate sm3 sum of string, in lower case hex
+func Sm3(src string) string {
+	return hex.EncodeToString(Sm3Bytes([]byte(src)))
+}
+
+// calculate sm3 sum of bytes
+func Sm3Bytes(src []byte) []byte {
+	return sm3.Sm3Sum(src)
+}
+
+// calculate sm3 sum of bytes, in the given encoding
+func Sm3Encode(src []byte, enc Encoding) (string, error) {
+	return enc.EncodeToString(Sm3Bytes(src))
+}
+
+// calculate sm3 sum of file, in lower case hex
+func Sm3File(path string) (string, error) {
+	sums, err := DigestFile(path, SM3)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sums[SM3]), nil
+}
+
+// calculate HMAC-SM3 of data
+func HmacSm3(key, data []byte) []byte {
+	h := NewHmacSm3(key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// calculate HMAC-SM3 of data, in the given encoding
+func HmacSm3Encode(key, data []byte, enc Encoding) (string, error) {
+	return enc.EncodeToString(HmacSm3(key, data))
+}
+
+// create a streaming sm3 hash.Hash
+func NewSm3() hash.Hash {
+	return sm3.New()
+}
+
+// create a streaming HMAC-SM3 hash.Hash
+func NewHmacSm3(key []byte) hash.Hash {
+	return hmac.New(sm3.New, key)
+}
@@ -0,0 +1,70 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSm3(t *testing.T) {
+	expect := "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"
+	assert.Equal(t, expect, Sm3("abc"))
+	assert.Equal(t, expect, hex.EncodeToString(Sm3Bytes([]byte("abc"))))
+	encoded, err := Sm3Encode([]byte("abc"), BASE64)
+	assert.Nil(t, err)
+	assert.Equal(t, "Zsfw9GLu7dnR8tRr3BDk4kFnxIdc8veiKX2gK49LqOA=", encoded)
+
+	h := NewSm3()
+	h.Write([]byte("a"))
+	h.Write([]byte("bc"))
+	assert.Equal(t, expect, hex.EncodeToString(h.Sum(nil)))
+
+	path := filepath.Join(t.TempDir(), "abc.txt")
+	assert.Nil(t, os.WriteFile(path, []byte("abc"), 0644))
+	sum, err := Sm3File(path)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, sum)
+	assert.Equal(t, Md5("abc"), Md5File(path))
+	_, err = Sm3File(path + ".missing")
+	assert.NotNil(t, err)
+}
+
+func TestHmacSm3(t *testing.T) {
+	// openssl dgst -sm3 -hmac Jefe
+	expect := "2e87f1d16862e6d964b50a5200bf2b10b764faa9680a296a2405f24bec39f882"
+	key, data := []byte("Jefe"), []byte("what do ya want for nothing?")
+	assert.Equal(t, expect, hex.EncodeToString(HmacSm3(key, data)))
+	encoded, err := HmacSm3Encode(key, data, HEX)
+	assert.Nil(t, err)
+	assert.Equal(t, expect, encoded)
+
+	h := NewHmacSm3(key)
+	h.Write(data)
+	assert.Equal(t, expect, hex.EncodeToString(h.Sum(nil)))
+}
+
+func TestEncoding(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x01}
+	for _, enc := range []Encoding{HEX, HEXUPPER, BASE64, BASE64URL} {
+		encoded, err := enc.EncodeToString(data)
+		assert.Nil(t, err)
+		decoded, err := enc.DecodeString(encoded)
+		assert.Nil(t, err)
+		assert.Equal(t, data, decoded)
+	}
+	encoded, _ := HEXUPPER.EncodeToString(data)
+	assert.Equal(t, "FBFF01", encoded)
+	encoded, _ = BASE64URL.EncodeToString(data)
+	assert.Equal(t, "-_8B", encoded)
+
+	_, err := Encoding("BASE32").EncodeToString(data)
+	assert.True(t, errors.Is(err, ErrInvalidEncoding))
+	_, err = Encoding("BASE32").DecodeString("ab")
+	assert.True(t, errors.Is(err, ErrInvalidEncoding))
+	_, err = Sm3Encode(data, "")
+	assert.True(t, errors.Is(err, ErrInvalidEncoding))
+}
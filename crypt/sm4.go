@@ -1,8 +1,6 @@
 package crypt
 
 import (
-	"crypto/cipher"
-
 	"github.com/tjfoc/gmsm/sm4"
 )
 
@@ -67,73 +65,20 @@ func SM4DecryptBlocks(key, src []byte) (result []byte, err error) {
 }
 
 // encrtyp data with SM4
+// keyiv is the nonce in GCM mode, in GCM and stream modes a random one is generated and prepended when it is empty
 func SM4Encrypt(plainText, key, keyiv []byte, mode Mode, padding Padding) ([]byte, error) {
-	block, err := sm4.NewCipher(key)
+	c, err := newCipher(SM4, key, WithMode(mode), WithPadding(padding), WithIV(keyiv))
 	if err != nil {
 		return nil, err
 	}
-	if len(keyiv) == 0 {
-		keyiv = make([]byte, sm4.BlockSize)
-	}
-	blockSize := block.BlockSize()
-	var origData []byte
-	switch padding {
-	case PKCS5:
-		origData = PKCS5Padding(plainText, blockSize)
-	case PKCS7:
-		origData = PKCS7Padding(plainText, blockSize)
-	case ZERO:
-		origData = ZeroPadding(plainText, blockSize)
-	case NONE:
-		//
-	default:
-		origData = PKCS5Padding(plainText, blockSize)
-	}
-	var blockMode cipher.BlockMode
-	switch mode {
-	case CBC:
-		blockMode = cipher.NewCBCEncrypter(block, keyiv)
-	case ECB:
-		blockMode = NewECBEncrypter(block)
-	default:
-		blockMode = cipher.NewCBCEncrypter(block, keyiv)
-	}
-	cryted := make([]byte, len(origData))
-	blockMode.CryptBlocks(cryted, origData)
-	return cryted, nil
+	return c.Encrypt(plainText)
 }
 
 // decrypt data with SM4
 func SM4Decrypt(cipherText, key, keyiv []byte, mode Mode, padding Padding) ([]byte, error) {
-	block, err := sm4.NewCipher(key)
+	c, err := newCipher(SM4, key, WithMode(mode), WithPadding(padding), WithIV(keyiv))
 	if err != nil {
 		return nil, err
 	}
-	if len(keyiv) == 0 {
-		keyiv = make([]byte, sm4.BlockSize)
-	}
-	var blockMode cipher.BlockMode
-	switch mode {
-	case CBC:
-		blockMode = cipher.NewCBCDecrypter(block, keyiv)
-	case ECB:
-		blockMode = NewECBDecrypter(block)
-	default:
-		blockMode = cipher.NewCBCDecrypter(block, keyiv)
-	}
-	origData := make([]byte, len(cipherText))
-	blockMode.CryptBlocks(origData, cipherText)
-	switch padding {
-	case PKCS5:
-		origData = PKCS5UnPadding(origData)
-	case PKCS7:
-		origData = PKCS7UnPadding(origData)
-	case ZERO:
-		origData = ZeroUnPadding(origData)
-	case NONE:
-		//
-	default:
-		origData = PKCS5UnPadding(origData)
-	}
-	return origData, nil
+	return c.Decrypt(cipherText)
 }
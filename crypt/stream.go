@@ -0,0 +1,64 @@
+package crypt
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// isStreamMode 是否为流模式(CTR/CFB/OFB), 流模式不需要填充
+func isStreamMode(mode Mode) bool {
+	return mode == CTR || mode == CFB || mode == OFB
+}
+
+// newStream 根据模式创建流加密器
+// iv长度必须与分组长度一致
+func newStream(block cipher.Block, iv []byte, mode Mode, encrypt bool) (cipher.Stream, error) {
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("%w: length must be %d in mode %s", ErrInvalidIV, block.BlockSize(), mode)
+	}
+	switch mode {
+	case CTR:
+		return cipher.NewCTR(block, iv), nil
+	case CFB:
+		if encrypt {
+			return cipher.NewCFBEncrypter(block, iv), nil
+		}
+		return cipher.NewCFBDecrypter(block, iv), nil
+	case OFB:
+		return cipher.NewOFB(block, iv), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMode, mode)
+	}
+}
+
+// streamCrypt 使用流模式加密或解密
+// iv为空时, 加密随机生成iv并作为密文前缀: iv|ciphertext, 解密从密文前缀读取iv
+func streamCrypt(block cipher.Block, iv []byte, mode Mode, src []byte, encrypt bool) ([]byte, error) {
+	var dst, out []byte
+	if len(iv) > 0 {
+		dst = make([]byte, len(src))
+		out = dst
+	} else if encrypt {
+		out = make([]byte, block.BlockSize()+len(src))
+		iv, dst = out[:block.BlockSize()], out[block.BlockSize():]
+		if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+			return nil, err
+		}
+	} else {
+		if len(src) < block.BlockSize() {
+			return nil, fmt.Errorf("%w: missing iv prefix in mode %s", ErrInvalidIV, mode)
+		}
+		iv, src = src[:block.BlockSize()], src[block.BlockSize():]
+		dst = make([]byte, len(src))
+		out = dst
+	}
+
+	stream, err := newStream(block, iv, mode, encrypt)
+	if err != nil {
+		return nil, err
+	}
+	stream.XORKeyStream(dst, src)
+	return out, nil
+}
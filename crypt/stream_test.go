@@ -0,0 +1,80 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAESStreamVector(t *testing.T) {
+	// NIST SP 800-38A F.3.1, F.4.1, F.5.1
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	plain, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172a")
+	cases := []struct {
+		mode   Mode
+		iv     string
+		expect string
+	}{
+		{CTR, "f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff", "874d6191b620e3261bef6864990db6ce"},
+		{CFB, "000102030405060708090a0b0c0d0e0f", "3b3fd92eb72dad20333449f8e83cfb4a"},
+		{OFB, "000102030405060708090a0b0c0d0e0f", "3b3fd92eb72dad20333449f8e83cfb4a"},
+	}
+	for _, c := range cases {
+		iv, _ := hex.DecodeString(c.iv)
+		encrypted, err := AESEncrypt(plain, key, iv, c.mode, NONE)
+		assert.Nil(t, err, c.mode)
+		assert.Equal(t, c.expect, hex.EncodeToString(encrypted), c.mode)
+
+		decrypted, err := AESDecrypt(encrypted, key, iv, c.mode, NONE)
+		assert.Nil(t, err, c.mode)
+		assert.Equal(t, plain, decrypted, c.mode)
+	}
+}
+
+func TestStreamModes(t *testing.T) {
+	plain := []byte("stream modes need no padding")
+	key16 := []byte("1234567890abcdef")
+	key24 := []byte("1234567890abcdef12345678")
+	iv8 := []byte("12345678")
+
+	for _, mode := range []Mode{CTR, CFB, OFB} {
+		encrypted, err := SM4Encrypt(plain, key16, key16, mode, NONE)
+		assert.Nil(t, err, mode)
+		assert.Equal(t, len(plain), len(encrypted), mode)
+		decrypted, err := SM4Decrypt(encrypted, key16, key16, mode, NONE)
+		assert.Nil(t, err, mode)
+		assert.Equal(t, plain, decrypted, mode)
+
+		encrypted, err = DES3Encrypt(plain, key24, iv8, mode, NONE)
+		assert.Nil(t, err, mode)
+		decrypted, err = DES3Decrypt(encrypted, key24, iv8, mode, NONE)
+		assert.Nil(t, err, mode)
+		assert.Equal(t, plain, decrypted, mode)
+
+		// des uses the key as iv, which would repeat the keystream
+		_, err = DESEncrypt(plain, iv8, mode, NONE)
+		assert.True(t, errors.Is(err, ErrInvalidMode), mode)
+		_, err = DESDecrypt(plain, iv8, mode, NONE)
+		assert.True(t, errors.Is(err, ErrInvalidMode), mode)
+
+		// padding makes no sense in stream mode
+		_, err = AESEncrypt(plain, key16, key16, mode, PKCS7)
+		assert.NotNil(t, err, mode)
+		_, err = DES3Decrypt(plain, key24, iv8, mode, ZERO)
+		assert.NotNil(t, err, mode)
+
+		// iv must be exactly one block
+		_, err = AESEncrypt(plain, key16, iv8, mode, NONE)
+		assert.NotNil(t, err, mode)
+
+		// an empty iv is generated and prepended
+		encrypted, err = SM4Encrypt(plain, key16, nil, mode, NONE)
+		assert.Nil(t, err, mode)
+		assert.Equal(t, len(plain)+16, len(encrypted), mode)
+		decrypted, err = SM4Decrypt(encrypted, key16, nil, mode, NONE)
+		assert.Nil(t, err, mode)
+		assert.Equal(t, plain, decrypted, mode)
+	}
+}